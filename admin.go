@@ -0,0 +1,116 @@
+package ttlcache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AdminResponse is the answer to a single admin command, sent back as one line of JSON
+type AdminResponse struct {
+	Error     string           `json:"error,omitempty"`
+	Keys      []string         `json:"keys,omitempty"`
+	Found     bool             `json:"found,omitempty"`
+	Value     string           `json:"value,omitempty"`
+	Remaining string           `json:"remaining,omitempty"`
+	Stats     map[string]int64 `json:"stats,omitempty"`
+}
+
+// ServeAdmin accepts connections on the listener, typically a unix socket, and answers control commands
+// so operators can inspect a live cache. Every command is a single line, the response is a single line of JSON
+// holding an AdminResponse. The supported commands are:
+//
+//	KEYS [substring]  list the keys, optionally only those containing substring
+//	GET <key>         show the value of a key without extending its TTL
+//	DEL <key>         remove a key
+//	STATS             show the metrics and the item count
+//	PURGE             remove all keys
+//
+// ServeAdmin always returns a non-nil error, like http.Serve does. Close the listener to stop serving.
+// The ttlcachectl command in cmd/ttlcachectl is a client for this protocol.
+func (cache *Cache) ServeAdmin(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go cache.serveAdminConn(conn)
+	}
+}
+
+func (cache *Cache) serveAdminConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		if err := encoder.Encode(cache.adminCommand(scanner.Text())); err != nil {
+			return
+		}
+	}
+}
+
+func (cache *Cache) adminCommand(line string) AdminResponse {
+	command, argument := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		command, argument = line[:i], line[i+1:]
+	}
+
+	switch strings.ToUpper(command) {
+	case "KEYS":
+		keys := cache.Keys()
+		filtered := keys[:0]
+		for _, key := range keys {
+			if strings.Contains(key, argument) {
+				filtered = append(filtered, key)
+			}
+		}
+		sort.Strings(filtered)
+		return AdminResponse{Keys: filtered}
+	case "GET":
+		data, expireAt, exists := cache.peek(argument)
+		if !exists {
+			return AdminResponse{}
+		}
+		response := AdminResponse{Found: true, Value: fmt.Sprintf("%v", data), Remaining: "never"}
+		if !expireAt.IsZero() {
+			cache.mutex.Lock()
+			clock := cache.clock
+			cache.mutex.Unlock()
+			response.Remaining = expireAt.Sub(clock.Now()).Round(time.Millisecond).String()
+		}
+		return response
+	case "DEL":
+		return AdminResponse{Found: cache.Remove(argument)}
+	case "STATS":
+		metrics := cache.GetMetrics()
+		return AdminResponse{Stats: map[string]int64{
+			"count":      int64(cache.Count()),
+			"inserted":   metrics.Inserted,
+			"retrievals": metrics.Retrievals,
+			"hits":       metrics.Hits,
+			"misses":     metrics.Misses,
+			"evicted":    metrics.Evicted,
+			"queue":      int64(metrics.QueueLength),
+			"queue_max":  int64(metrics.QueueMaxLength),
+			"pushes":     metrics.QueuePushes,
+			"pops":       metrics.QueuePops,
+			"removals":   metrics.QueueRemovals,
+			"fixes":      metrics.QueueFixes,
+			// the rates are rounded to whole operations per second
+			"pushes_per_second":   int64(math.Round(metrics.QueuePushRate)),
+			"pops_per_second":     int64(math.Round(metrics.QueuePopRate)),
+			"removals_per_second": int64(math.Round(metrics.QueueRemovalRate)),
+			"fixes_per_second":    int64(math.Round(metrics.QueueFixRate)),
+		}}
+	case "PURGE":
+		cache.Purge()
+		return AdminResponse{}
+	default:
+		return AdminResponse{Error: fmt.Sprintf("unknown command %q", command)}
+	}
+}
@@ -0,0 +1,86 @@
+package ttlcache
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_ServeAdmin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ttlcache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "admin.sock"))
+	assert.Nil(t, err)
+
+	cache := NewCache()
+	defer cache.Close()
+	served := make(chan error)
+	go func() { served <- cache.ServeAdmin(listener) }()
+
+	cache.SetWithTTL("user:1", "alice", time.Minute)
+	cache.Set("user:2", "bob")
+	cache.Set("config", 42)
+
+	conn, err := net.Dial("unix", listener.Addr().String())
+	assert.Nil(t, err)
+	reader := bufio.NewReader(conn)
+	command := func(line string) AdminResponse {
+		var response AdminResponse
+		fmt.Fprintln(conn, line)
+		answer, err := reader.ReadBytes('\n')
+		assert.Nil(t, err)
+		assert.Nil(t, json.Unmarshal(answer, &response))
+		return response
+	}
+
+	assert.Equal(t, []string{"user:1", "user:2"}, command("KEYS user:").Keys)
+	assert.Equal(t, []string{"config", "user:1", "user:2"}, command("keys").Keys)
+
+	response := command("GET user:1")
+	assert.True(t, response.Found)
+	assert.Equal(t, "alice", response.Value)
+	assert.NotEqual(t, "never", response.Remaining)
+	assert.Equal(t, "never", command("GET config").Remaining)
+	assert.False(t, command("GET nope").Found)
+	assert.Equal(t, int64(0), cache.GetMetrics().Retrievals, "Expected GET to not count as a retrieval")
+
+	assert.True(t, command("DEL user:2").Found)
+	assert.False(t, command("DEL user:2").Found)
+	assert.Equal(t, int64(2), command("STATS").Stats["count"])
+	assert.Equal(t, "", command("PURGE").Error)
+	assert.Equal(t, 0, cache.Count())
+	assert.NotEqual(t, "", command("FLY away").Error)
+
+	conn.Close()
+	listener.Close()
+	assert.NotNil(t, <-served)
+}
+
+// fixedClock is a Clock which always shows the same time
+type fixedClock struct {
+	realClock
+	now time.Time
+}
+
+func (clock fixedClock) Now() time.Time {
+	return clock.now
+}
+
+func TestCache_AdminGetUsesClock(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetClock(fixedClock{now: time.Now().Add(-time.Hour)})
+
+	cache.SetWithTTL("key", "value", time.Minute)
+	assert.Equal(t, "1m0s", cache.adminCommand("GET key").Remaining, "Expected the remaining TTL by the cache clock")
+}
@@ -0,0 +1,160 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+const (
+	// asyncQueueSize is the number of writes SetAsync queues before it drops further writes
+	asyncQueueSize = 4096
+	// asyncBatchSize is the largest number of queued writes applied under one lock of the cache
+	asyncBatchSize = 256
+	// asyncFlushInterval is how long a write may wait for its batch to fill up
+	asyncFlushInterval = 5 * time.Millisecond
+)
+
+// asyncWrite is a write queued by SetAsync, or a flush marker when flushed is set
+type asyncWrite[K comparable] struct {
+	key     K
+	data    interface{}
+	ttl     time.Duration
+	flushed chan struct{}
+}
+
+// SetAsync queues the write and returns right away, for writers to which latency matters more than immediate
+// visibility, like telemetry. Queued writes are applied in batches every few milliseconds. When the queue is full
+// the write is dropped and counted in the AsyncDropped metric. FlushAsync waits for the queued writes.
+func (cache *CacheOf[K]) SetAsync(key K, data interface{}, ttl time.Duration) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	// the write is queued under the lock, so Close cannot miss it once it stops the queue
+	queue := cache.asyncQueue()
+	if queue == nil {
+		return
+	}
+	select {
+	case queue <- asyncWrite[K]{key: key, data: data, ttl: ttl}:
+	default:
+		cache.metrics.AsyncDropped++
+	}
+}
+
+// FlushAsync waits until the writes queued by SetAsync so far are applied
+func (cache *CacheOf[K]) FlushAsync() {
+	cache.mutex.Lock()
+	queue := cache.asyncQueue()
+	cache.mutex.Unlock()
+	if queue == nil {
+		return
+	}
+	flushed := make(chan struct{})
+	select {
+	case queue <- asyncWrite[K]{flushed: flushed}:
+	case <-cache.done:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-cache.done:
+	}
+}
+
+// asyncQueue returns the queue of SetAsync and starts its worker on first use, it is nil once the cache is closing.
+// The cache mutex must be held.
+func (cache *CacheOf[K]) asyncQueue() chan asyncWrite[K] {
+	if cache.closing {
+		return nil
+	}
+	if cache.asyncWrites == nil {
+		cache.asyncWrites = make(chan asyncWrite[K], asyncQueueSize)
+		cache.asyncStop = make(chan struct{})
+		cache.asyncStopped = make(chan struct{})
+		go cache.applyAsyncWrites(cache.asyncWrites, cache.asyncStop, cache.asyncStopped)
+	}
+	return cache.asyncWrites
+}
+
+// stopAsyncWrites waits until the worker of SetAsync has applied every queued write, Close calls it before the
+// cache shuts down so the writes are kept, and are in the final snapshot
+func (cache *CacheOf[K]) stopAsyncWrites() {
+	cache.mutex.Lock()
+	stop, stopped := cache.asyncStop, cache.asyncStopped
+	cache.mutex.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-stopped
+}
+
+// applyAsyncWrites applies the queued writes in batches until stop is closed, then applies the rest of the queue
+func (cache *CacheOf[K]) applyAsyncWrites(queue chan asyncWrite[K], stop chan struct{}, stopped chan struct{}) {
+	defer close(stopped)
+	batch := make([]asyncWrite[K], 0, asyncBatchSize)
+	// flush is only set while the batch has writes, so an idle worker does not wake up
+	var flush <-chan time.Time
+	for {
+		select {
+		case write := <-queue:
+			batch = append(batch, write)
+			if len(batch) == 1 {
+				flush = time.After(asyncFlushInterval)
+			}
+			if len(batch) < asyncBatchSize && write.flushed == nil {
+				continue
+			}
+		case <-flush:
+		case <-stop:
+			for {
+				select {
+				case write := <-queue:
+					batch = append(batch, write)
+				default:
+					cache.applyBatch(batch)
+					return
+				}
+			}
+		}
+		cache.applyBatch(batch)
+		batch = batch[:0]
+		flush = nil
+	}
+}
+
+// applyBatch stores the writes under a single lock, unless middleware has to see every write
+func (cache *CacheOf[K]) applyBatch(batch []asyncWrite[K]) {
+	if len(batch) == 0 {
+		return
+	}
+	cache.mutex.Lock()
+	perWrite := cache.setChain != nil || (cache.valueCopier != nil && cache.copyOnSet)
+	newItemCallback := cache.newItemCallback
+	var added []asyncWrite[K]
+	if !perWrite {
+		for _, write := range batch {
+			if write.flushed != nil {
+				continue
+			}
+			key, isNew := cache.set(write.key, write.data, write.ttl, itemOptions[K]{})
+			if isNew && newItemCallback != nil {
+				added = append(added, asyncWrite[K]{key: key, data: write.data})
+			}
+		}
+	}
+	cache.mutex.Unlock()
+
+	for _, write := range batch {
+		if perWrite && write.flushed == nil {
+			cache.SetWithTTL(write.key, write.data, write.ttl)
+		}
+	}
+	for _, write := range added {
+		newItemCallback(write.key, write.data)
+	}
+	cache.notifyExpiration()
+	for _, write := range batch {
+		if write.flushed != nil {
+			close(write.flushed)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ttlcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetAsync(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	added := make(chan string, 10)
+	cache.SetNewItemCallback(func(key string, value interface{}) {
+		added <- key
+	})
+	cache.SetAsync("a", 1, time.Minute)
+	cache.SetAsync("b", 2, ItemExpireWithGlobalTTL)
+	cache.FlushAsync()
+	data, exists := cache.Get("a")
+	assert.True(t, exists)
+	assert.Equal(t, 1, data)
+	ttl, _ := cache.GetTTL("a")
+	assert.Equal(t, time.Minute, ttl)
+	assert.Equal(t, 2, len(added))
+
+	// without a flush the batch is applied after a few milliseconds
+	cache.SetAsync("c", 3, 0)
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, cache.Has("c"))
+	assert.Equal(t, int64(0), cache.GetMetrics().AsyncDropped)
+}
+
+func TestCache_SetAsyncAfterClose(t *testing.T) {
+	cache := NewCache()
+	cache.SetAsync("a", 1, 0)
+	cache.Close()
+	cache.SetAsync("b", 2, 0)
+	cache.FlushAsync()
+	assert.Equal(t, 0, cache.Count())
+}
+
+func TestCache_SetAsyncBeforeClose(t *testing.T) {
+	storage := &memorySnapshotStorage{}
+	cache := NewCache()
+	cache.StartSnapshotUploads(storage, 24*time.Hour, nil)
+	cache.SetAsync("key", "value", 0)
+	cache.Close()
+
+	// the queued write is applied before the final snapshot is taken
+	restored := NewCache()
+	defer restored.Close()
+	assert.Nil(t, restored.RestoreSnapshot(context.Background(), storage))
+	data, exists := restored.Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+}
@@ -1,6 +1,7 @@
 package bench
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -12,7 +13,7 @@ func BenchmarkCacheSetWithoutTTL(b *testing.B) {
 	defer cache.Close()
 
 	for n := 0; n < b.N; n++ {
-		cache.Set(string(n%1000000), "value")
+		cache.Set(strconv.Itoa(n%1000000), "value")
 	}
 }
 
@@ -22,7 +23,7 @@ func BenchmarkCacheSetWithGlobalTTL(b *testing.B) {
 
 	cache.SetTTL(time.Duration(50 * time.Millisecond))
 	for n := 0; n < b.N; n++ {
-		cache.Set(string(n%1000000), "value")
+		cache.Set(strconv.Itoa(n%1000000), "value")
 	}
 }
 
@@ -31,6 +32,6 @@ func BenchmarkCacheSetWithTTL(b *testing.B) {
 	defer cache.Close()
 
 	for n := 0; n < b.N; n++ {
-		cache.SetWithTTL(string(n%1000000), "value", time.Duration(50*time.Millisecond))
+		cache.SetWithTTL(strconv.Itoa(n%1000000), "value", time.Duration(50*time.Millisecond))
 	}
 }
@@ -0,0 +1,53 @@
+package ttlcache
+
+import (
+	"context"
+)
+
+// BulkLoaderFunc loads the values of several missing keys in one call, such as an MGET of the data source. Keys
+// without a value are left out of the result.
+type BulkLoaderFunc[K comparable] func(ctx context.Context, keys []K) (map[K]interface{}, error)
+
+// SetBulkLoader sets the loader GetManyWithLoader calls for the missing keys
+func (cache *CacheOf[K]) SetBulkLoader(loader BulkLoaderFunc[K]) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.bulkLoader = loader
+}
+
+// GetManyWithLoader returns the values of the keys, the missing ones are loaded with a single call of the bulk loader
+// and stored with the global TTL. Keys which are neither cached nor loaded are left out of the result. When the load
+// fails the cached values are returned with the error.
+func (cache *CacheOf[K]) GetManyWithLoader(ctx context.Context, keys []K) (map[K]interface{}, error) {
+	values := cache.GetMany(keys...)
+	var missing []K
+	for _, key := range keys {
+		if _, exists := values[key]; !exists {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	cache.mutex.Lock()
+	loader, closed := cache.bulkLoader, cache.isShutDown
+	cache.mutex.Unlock()
+	if closed {
+		return values, ErrCacheClosed
+	}
+	if loader == nil {
+		return values, ErrNoLoader
+	}
+	loaded, err := loader(ctx, missing)
+	if err != nil {
+		return values, err
+	}
+	for _, key := range missing {
+		if data, exists := loaded[key]; exists {
+			cache.Set(key, data)
+			values[key] = data
+		}
+	}
+	return values, nil
+}
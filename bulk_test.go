@@ -0,0 +1,42 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetManyWithLoader(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("a", "cached")
+
+	_, err := cache.GetManyWithLoader(context.Background(), []string{"a", "b"})
+	assert.Equal(t, ErrNoLoader, err)
+
+	var calls [][]string
+	cache.SetBulkLoader(func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		calls = append(calls, keys)
+		return map[string]interface{}{"b": "loaded b", "c": "loaded c"}, nil
+	})
+	values, err := cache.GetManyWithLoader(context.Background(), []string{"a", "b", "c", "d"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "cached", "b": "loaded b", "c": "loaded c"}, values)
+	assert.Equal(t, [][]string{{"b", "c", "d"}}, calls, "Expected a single load of the missing keys")
+	assert.Equal(t, 3, cache.Count())
+
+	values, err = cache.GetManyWithLoader(context.Background(), []string{"a", "b", "c"})
+	assert.Nil(t, err)
+	assert.Len(t, values, 3)
+	assert.Len(t, calls, 1, "Expected no load when all keys are cached")
+
+	failing := errors.New("backend down")
+	cache.SetBulkLoader(func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return nil, failing
+	})
+	values, err = cache.GetManyWithLoader(context.Background(), []string{"a", "e"})
+	assert.Equal(t, failing, err)
+	assert.Equal(t, map[string]interface{}{"a": "cached"}, values)
+}
@@ -1,34 +1,121 @@
 package ttlcache
 
 import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // CheckExpireCallback is used as a callback for an external check on Item expiration
-type checkExpireCallback func(key string, value interface{}) bool
+type checkExpireCallback[K comparable] func(key K, value interface{}) bool
 
 // ExpireCallback is used as a callback on Item expiration or when notifying of an Item new to the cache
-type expireCallback func(key string, value interface{})
+type expireCallback[K comparable] func(key K, value interface{})
 
-// Cache is a synchronized map of items that can auto-expire once stale
+// Cache is a synchronized map of items with string keys that can auto-expire once stale
 type Cache struct {
+	CacheOf[string]
+	namespaces map[string]*Namespace
+	tenants    map[string]*Tenant
+	// tenantPrefixes are the tenants by the normalized prefix of their keys
+	tenantPrefixes map[string]*Tenant
+}
+
+// CacheOf is a synchronized map of items that can auto-expire once stale, with keys of any comparable type such as
+// integers or structs. Callbacks, events and the expiration order work the same for every key type.
+type CacheOf[K comparable] struct {
+	// fastHits counts the hits of the lock-free path of SetReadMostly, it comes first to be aligned for atomic access
+	fastHits int64
+
 	mutex                  sync.Mutex
 	ttl                    time.Duration
-	items                  map[string]*Item
-	expireCallback         expireCallback
-	checkExpireCallback    checkExpireCallback
-	newItemCallback        expireCallback
-	priorityQueue          *priorityQueue
+	items                  StoreOf[K]
+	expireCallback         expireCallback[K]
+	checkExpireCallback    checkExpireCallback[K]
+	newItemCallback        expireCallback[K]
+	removalCallback        func(key K, value interface{}, reason EventType)
+	priorityQueue          *priorityQueue[K]
 	expirationNotification chan bool
 	expirationTime         time.Time
 	skipTTLExtension       bool
+	expirationDisabled     bool
+	peakItems              int
+	maxTTLExtension        time.Duration
+	maxLifetime            time.Duration
 	shutdownSignal         chan (chan struct{})
 	isShutDown             bool
+	closing                bool
+	metrics                Metrics
+	subscriptions          map[*SubscriptionOf[K]]struct{}
+	lastSweep              time.Time
+	sizeLimit              int
+	weigher                func(key K, value interface{}) int64
+	maxCost                int64
+	totalCost              int64
+	ttlJitter              float64
+	ttlFunc                TTLFunc[K]
+	equal                  func(a, b interface{}) bool
+	valueCopier            func(value interface{}) interface{}
+	copyOnSet              bool
+	keyNormalizer          func(key K) K
+	lastVersion            uint64
+	middleware             []MiddlewareOf[K]
+	setChain               SetFunc[K]
+	getChain               GetFunc[K]
+	observer               itemObserver[K]
+	keyLocksMutex          sync.Mutex
+	keyLocks               map[K]*keyLock
+	loader                 ResultLoaderFunc[K]
+	retryPolicy            RetryPolicy
+	loadSlots              chan struct{}
+	loads                  map[K]*loadCall
+	loadingPolicy          LoadingPolicy
+	waitForLoadSlot        bool
+	readThrough            bool
+	bulkLoader             BulkLoaderFunc[K]
+	refreshAhead           float64
+	refreshes              map[K]struct{}
+	staleGrace             time.Duration
+	staleValues            map[K]staleValue
+	staleOrder             []staleKey[K]
+	tags                   map[string]map[K]struct{}
+	secondLevel            SecondLevel[K]
+	fetches                map[K]*fetchCall
+	prefetchConcurrency    int
+	prefetchSlots          chan struct{}
+	prefetchCallback       func(key K, err error)
+	hotKeysStop            chan struct{}
+	asyncWrites            chan asyncWrite[K]
+	asyncStop              chan struct{}
+	asyncStopped           chan struct{}
+	snapshotErr            error
+	// scanItems are the items in the order of insertion for Scan, including scanRemoved removed ones
+	scanItems   []*ItemOf[K]
+	scanRemoved int
+	lastSeq     uint64
+	clock       Clock
+	// readOnly holds the *readSnapshot of SetReadMostly, readMisses counts the locked lookups since it was discarded
+	readOnly   atomic.Value
+	readMostly bool
+	readMisses int
+	// done is closed by Close to stop background workers, which register themselves in workers
+	done    chan struct{}
+	workers sync.WaitGroup
+	// runningCallbacks counts the callback goroutines, callbacksDone is signalled when it drops to zero
+	runningCallbacks int
+	callbacksDone    *sync.Cond
 }
 
-func (cache *Cache) GetItem(key string) (*Item, bool, bool) {
-	item, exists := cache.items[key]
+// GetItem looks up an item and touches it, it returns the item, whether it exists, and whether the expiration
+// goroutine must be notified. The cache mutex must be held.
+//
+// Deprecated: GetItemMeta returns a copy of the item that is safe to use.
+func (cache *CacheOf[K]) GetItem(key K) (*ItemOf[K], bool, bool) {
+	item, exists := cache.items.Get(key)
 	if !exists || item.expired() {
 		return nil, false, false
 	}
@@ -38,26 +125,34 @@ func (cache *Cache) GetItem(key string) (*Item, bool, bool) {
 			item.TTL = cache.ttl
 		}
 
-		if !cache.skipTTLExtension {
-			item.touch()
+		if cache.extendsOnHit(item) {
+			cache.touch(item)
+			if cache.maxTTLExtension > 0 && !item.writeExpireAt.IsZero() &&
+				item.ExpireAt.After(item.writeExpireAt.Add(cache.maxTTLExtension)) {
+				item.ExpireAt = item.writeExpireAt.Add(cache.maxTTLExtension)
+			}
 		}
 		cache.priorityQueue.update(item)
 	}
 
 	expirationNotification := false
-	if cache.expirationTime.After(time.Now().Add(item.TTL)) {
+	if cache.expirationTime.After(cache.clock.Now().Add(item.TTL)) {
 		expirationNotification = true
 	}
 	return item, exists, expirationNotification
 }
 
-func (cache *Cache) startExpirationProcessing() {
-	timer := time.NewTimer(time.Hour)
+func (cache *CacheOf[K]) startExpirationProcessing() {
+	// the timer is created again when SetClock replaced the clock
+	var timer Timer
+	var timerClock Clock
 	for {
 		var sleepTime time.Duration
 		cache.mutex.Lock()
+		clock := cache.clock
+		now := clock.Now()
 		if cache.priorityQueue.Len() > 0 {
-			sleepTime = time.Until(cache.priorityQueue.items[0].ExpireAt)
+			sleepTime = cache.priorityQueue.items[0].ExpireAt.Sub(now)
 			if sleepTime < 0 && cache.priorityQueue.items[0].ExpireAt.IsZero() {
 				sleepTime = time.Hour
 			} else if sleepTime < 0 {
@@ -73,51 +168,23 @@ func (cache *Cache) startExpirationProcessing() {
 			sleepTime = time.Hour
 		}
 
-		cache.expirationTime = time.Now().Add(sleepTime)
+		cache.lastSweep = now
+		cache.expirationTime = cache.lastSweep.Add(sleepTime)
 		cache.mutex.Unlock()
 
-		timer.Reset(sleepTime)
+		if clock != timerClock {
+			timer, timerClock = clock.NewTimer(sleepTime), clock
+		} else {
+			timer.Reset(sleepTime)
+		}
 		select {
 		case shutdownFeedback := <-cache.shutdownSignal:
 			timer.Stop()
 			shutdownFeedback <- struct{}{}
 			return
-		case <-timer.C:
+		case <-timer.C():
 			timer.Stop()
-			cache.mutex.Lock()
-			if cache.priorityQueue.Len() == 0 {
-				cache.mutex.Unlock()
-				continue
-			}
-
-			// index will only be advanced if the current entry will not be evicted
-			i := 0
-			for item := cache.priorityQueue.items[i]; item.expired(); item = cache.priorityQueue.items[i] {
-
-				if cache.checkExpireCallback != nil {
-					if !cache.checkExpireCallback(item.key, item.Data) {
-						item.touch()
-						cache.priorityQueue.update(item)
-						i++
-						if i == cache.priorityQueue.Len() {
-							break
-						}
-						continue
-					}
-				}
-
-				cache.priorityQueue.remove(item)
-				delete(cache.items, item.key)
-				if cache.expireCallback != nil {
-					go cache.expireCallback(item.key, item.Data)
-				}
-				if cache.priorityQueue.Len() == 0 {
-					goto done
-				}
-			}
-		done:
-			cache.mutex.Unlock()
-
+			cache.sweep(clock.Now())
 		case <-cache.expirationNotification:
 			timer.Stop()
 			continue
@@ -125,82 +192,667 @@ func (cache *Cache) startExpirationProcessing() {
 	}
 }
 
-// Close calls Purge, and then stops the goroutine that does TTL checking, for a clean shutdown.
-// The cache is no longer cleaning up after the first call to Close, repeated calls are safe though.
-func (cache *Cache) Close() {
+// expiryCandidate is an expired item with the key and value it had when the sweep found it
+type expiryCandidate[K comparable] struct {
+	item  *ItemOf[K]
+	key   K
+	data  interface{}
+	check checkExpireCallback[K]
+}
+
+// ProcessExpirations synchronously does one sweep as if the current time were now, removing the items expired by then.
+// It is meant for tests, which can assert expirations without sleeping. The background sweeps keep running.
+func (cache *CacheOf[K]) ProcessExpirations(now time.Time) {
+	cache.sweep(now)
+}
+
+// sweep removes the items expired at now. The check expiration callbacks run without the cache locked, so they may do
+// I/O or use the cache, and items which were changed or removed in the meantime are left alone.
+func (cache *CacheOf[K]) sweep(now time.Time) {
+	cache.mutex.Lock()
+	// the expired items form a subtree at the root of the heap
+	var expired []*ItemOf[K]
+	stack := []int{0}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if i >= cache.priorityQueue.Len() || !cache.priorityQueue.items[i].expiredAt(now) {
+			continue
+		}
+		expired = append(expired, cache.priorityQueue.items[i])
+		stack = append(stack, 2*i+1, 2*i+2)
+	}
+
+	var candidates []expiryCandidate[K]
+	for _, item := range expired {
+		check := item.checkExpire
+		if check == nil {
+			check = cache.checkExpireCallback
+		}
+		if check == nil || item.outlivedAt(now) {
+			// the check expiration callback cannot extend the maximum lifetime
+			cache.expire(item)
+		} else {
+			candidates = append(candidates, expiryCandidate[K]{item: item, key: item.key, data: item.Data, check: check})
+		}
+	}
+	cache.compactIfShrunk()
+	cache.mutex.Unlock()
+	if len(candidates) == 0 {
+		return
+	}
+
+	expires := make([]bool, len(candidates))
+	for i, candidate := range candidates {
+		expires[i] = candidate.check(candidate.key, candidate.data)
+	}
+
+	cache.mutex.Lock()
+	for i, candidate := range candidates {
+		item := candidate.item
+		if current, exists := cache.items.Get(candidate.key); !exists || current != item || !item.expiredAt(now) {
+			continue
+		}
+		if expires[i] {
+			cache.expire(item)
+		} else {
+			cache.touch(item)
+			cache.priorityQueue.update(item)
+		}
+	}
+	cache.mutex.Unlock()
+}
+
+// expire removes an expired item, the cache mutex must be held
+func (cache *CacheOf[K]) expire(item *ItemOf[K]) {
+	cache.deleteItem(item, EventExpired)
+	cache.metrics.Evicted++
+	if cache.expireCallback != nil {
+		expireCallback := cache.expireCallback
+		cache.runCallback(func() { expireCallback(item.key, item.Data) })
+	}
+}
+
+// Close stops the goroutine that does TTL checking and the background workers, calls Purge, and waits for running
+// callbacks, see Drain, for a clean shutdown. Repeated calls are safe. A closed cache stays empty: writes are ignored,
+// or fail with ErrCacheClosed where they return an error, and lookups miss. Create a new cache instead of reusing a
+// closed one.
+func (cache *CacheOf[K]) Close() {
 
 	cache.mutex.Lock()
-	if !cache.isShutDown {
+	if !cache.closing {
+		// closing stops SetAsync, the queued writes are applied before the cache shuts down
+		cache.closing = true
+		cache.mutex.Unlock()
+		cache.stopAsyncWrites()
+		cache.mutex.Lock()
 		cache.isShutDown = true
 		cache.mutex.Unlock()
-		feedback := make(chan struct{})
-		cache.shutdownSignal <- feedback
-		<-feedback
+		if !cache.expirationDisabled {
+			feedback := make(chan struct{})
+			cache.shutdownSignal <- feedback
+			<-feedback
+		}
 		close(cache.shutdownSignal)
+		close(cache.done)
+		cache.workers.Wait()
+		cache.mutex.Lock()
+		cache.closeSubscriptions()
+		cache.mutex.Unlock()
 	} else {
 		cache.mutex.Unlock()
 	}
 	cache.Purge()
+	cache.Drain()
 }
 
 // Set is a thread-safe way to add new items to the map
-func (cache *Cache) Set(key string, data interface{}) {
+func (cache *CacheOf[K]) Set(key K, data interface{}) {
 	cache.SetWithTTL(key, data, ItemExpireWithGlobalTTL)
 }
 
 // SetWithTTL is a thread-safe way to add new items to the map with individual TTL
-func (cache *Cache) SetWithTTL(key string, data interface{}, ttl time.Duration) {
+func (cache *CacheOf[K]) SetWithTTL(key K, data interface{}, ttl time.Duration) {
 	cache.mutex.Lock()
+	set := cache.setChain
+	cache.mutex.Unlock()
+	if set != nil {
+		set(key, data, ttl)
+		return
+	}
+	cache.setWithTTL(key, data, ttl)
+}
+
+// setWithTTL is SetWithTTL without the middleware
+func (cache *CacheOf[K]) setWithTTL(key K, data interface{}, ttl time.Duration) {
+	cache.setWithOptions(key, data, ttl, itemOptions[K]{})
+}
+
+// SetMany stores all items with the same individual TTL under a single lock of the cache, and restores the
+// expiration order once instead of for every item. Without a size or cost limit, for which the order must stay
+// intact, this is faster than calling SetWithTTL for each item.
+func (cache *CacheOf[K]) SetMany(items map[K]interface{}, ttl time.Duration) {
+	cache.mutex.Lock()
+	if cache.setChain != nil || (cache.valueCopier != nil && cache.copyOnSet) {
+		cache.mutex.Unlock()
+		for key, data := range items {
+			cache.SetWithTTL(key, data, ttl)
+		}
+		return
+	}
+	batch := cache.sizeLimit == 0 && cache.maxCost == 0
+	if batch {
+		cache.priorityQueue.startBatch()
+	}
+	newItemCallback := cache.newItemCallback
+	var addedKeys []K
+	var addedData []interface{}
+	for key, data := range items {
+		key, isNew := cache.set(key, data, ttl, itemOptions[K]{})
+		if isNew && newItemCallback != nil {
+			addedKeys, addedData = append(addedKeys, key), append(addedData, data)
+		}
+	}
+	if batch {
+		cache.priorityQueue.endBatch()
+	}
+	cache.mutex.Unlock()
+
+	for i, key := range addedKeys {
+		newItemCallback(key, addedData[i])
+	}
+	cache.notifyExpiration()
+}
+
+// SetWithValidator stores the item with an individual TTL and a validator, such as an ETag or a hash of the value,
+// which GetValidator returns without touching the value. Other writes of the key clear the validator. Like the other
+// writes with options it bypasses the middleware, see Use.
+func (cache *CacheOf[K]) SetWithValidator(key K, data interface{}, ttl time.Duration, validator string) {
+	cache.setWithOptions(key, data, ttl, itemOptions[K]{validator: validator})
+}
+
+// SetWithCheckExpiration stores the item with an individual TTL and a check that decides, like the callback of
+// SetCheckExpirationCallback, whether the item expires or stays for another TTL. It replaces the global callback for
+// this item only, so the sweep does not ask about items that always expire. Other writes of the key clear the check.
+// It bypasses the middleware, see Use.
+func (cache *CacheOf[K]) SetWithCheckExpiration(key K, data interface{}, ttl time.Duration, check checkExpireCallback[K]) {
+	cache.setWithOptions(key, data, ttl, itemOptions[K]{checkExpire: check})
+}
+
+func (cache *CacheOf[K]) setWithOptions(key K, data interface{}, ttl time.Duration, options itemOptions[K]) {
+	data = cache.lockForSet(data)
+	key, isNew := cache.set(key, data, ttl, options)
+	cache.mutex.Unlock()
+	if isNew && cache.newItemCallback != nil {
+		cache.newItemCallback(key, data)
+	}
+	cache.notifyExpiration()
+}
+
+// lockForSet locks the cache and returns the value to store, copied when SetCopyOnSet is enabled. The copier runs
+// without the lock.
+func (cache *CacheOf[K]) lockForSet(data interface{}) interface{} {
+	cache.mutex.Lock()
+	if cache.valueCopier != nil && cache.copyOnSet {
+		copier := cache.valueCopier
+		cache.mutex.Unlock()
+		data = copier(data)
+		cache.mutex.Lock()
+	}
+	return data
+}
+
+// itemOptions are the settings of a single write of an item
+type itemOptions[K comparable] struct {
+	validator   string
+	checkExpire checkExpireCallback[K]
+	cost        int64
+	tags        []string
+	// expireAt is the absolute expiration time of SetWithExpireAt
+	expireAt    time.Time
+	touchOnHit  touchMode
+	maxLifetime time.Duration
+}
+
+// set stores the item with the cache mutex held, it returns the normalized key and whether the key is new.
+// The caller runs the new item callback and notifies the expiration goroutine after unlocking.
+// Nothing is stored once the cache is closed.
+func (cache *CacheOf[K]) set(key K, data interface{}, ttl time.Duration, options itemOptions[K]) (K, bool) {
+	key = cache.normalize(key)
+	if cache.isShutDown {
+		return key, false
+	}
 	item, exists, _ := cache.GetItem(key)
+	if ttl == 0 && cache.ttlFunc != nil && options.expireAt.IsZero() {
+		ttl = cache.ttlFunc(key, data)
+	}
+	if cache.expirationDisabled {
+		ttl = ItemNotExpire
+	}
+	weight, costReported := options.cost, options.cost != 0
+	if !costReported && cache.weigher != nil {
+		weight = cache.weigher(key, data)
+	}
+	if cache.maxCost > 0 && weight > cache.maxCost {
+		// evicting every other item would not make room for it, the value it was meant to replace goes anyway
+		if exists {
+			cache.evict(item)
+		}
+		return key, false
+	}
 
+	var oldData interface{}
 	if exists {
+		oldData = item.Data
 		item.Data = data
 		item.TTL = ttl
 	} else {
-		item = newItem(key, data, ttl)
-		cache.items[key] = item
+		if expired, found := cache.items.Get(key); found {
+			// the expiration goroutine did not get to it yet
+			cache.expire(expired)
+		}
+		cache.evictForSize(1)
+		item = newItem(key, data, ttl, cache.clock)
+		cache.metrics.Inserted++
 	}
 
+	maxLifetime := options.maxLifetime
+	if maxLifetime == 0 {
+		maxLifetime = cache.maxLifetime
+	}
+	if item.deadline.IsZero() && maxLifetime > 0 {
+		// the lifetime counts from the insert, rewrites do not restart it
+		item.deadline = item.createdAt.Add(maxLifetime)
+	}
+	item.globalTTL = item.TTL == 0
 	if item.TTL >= 0 && (item.TTL > 0 || cache.ttl > 0) {
 		if cache.ttl > 0 && item.TTL == 0 {
 			item.TTL = cache.ttl
 		}
-		item.touch()
+		cache.touch(item)
+		if !options.expireAt.IsZero() {
+			item.ExpireAt = options.expireAt
+		}
+	} else {
+		item.ExpireAt = time.Time{}
 	}
+	item.capLifetime()
+	item.writeExpireAt = item.ExpireAt
+	item.touchOnHit = options.touchOnHit
+
+	item.version = cache.nextVersion()
+	item.validator = options.validator
+	item.checkExpire = options.checkExpire
+	cache.setTags(item, options.tags)
+	oldWeight := item.weight
+	cache.totalCost -= item.weight
+	item.weight, item.costReported = weight, costReported
+	cache.totalCost += item.weight
 
 	if exists {
+		cache.invalidateReads()
 		cache.priorityQueue.update(item)
+		if cache.observer != nil {
+			cache.observer.itemUpdated(item, oldData, oldWeight)
+		}
+		cache.publish(EventUpdated, key, data)
 	} else {
-		cache.priorityQueue.push(item)
+		cache.insertItem(item)
+		cache.publish(EventInserted, key, data)
+	}
+	cache.evictForCost(item)
+	return key, !exists
+}
+
+// Get is a thread-safe way to lookup items
+// Every lookup, also touches the Item, hence extending it's life. With SetReadThrough missing keys are loaded.
+func (cache *CacheOf[K]) Get(key K) (interface{}, bool) {
+	data, exists := cache.lookup(key)
+	if exists {
+		return data, true
 	}
+	cache.mutex.Lock()
+	readThrough := cache.readThrough
+	cache.mutex.Unlock()
+	if !readThrough {
+		return nil, false
+	}
+	data, err := cache.loadMissing(context.Background(), key)
+	return data, err == nil
+}
 
+// lookup is Get without loading missing keys
+func (cache *CacheOf[K]) lookup(key K) (interface{}, bool) {
+	if data, ok := cache.getFast(key); ok {
+		return data, true
+	}
+	cache.mutex.Lock()
+	get := cache.getChain
 	cache.mutex.Unlock()
-	if !exists && cache.newItemCallback != nil {
-		cache.newItemCallback(key, data)
+	if get != nil {
+		return get(key)
 	}
-	cache.expirationNotification <- true
+	return cache.get(key)
 }
 
-// Get is a thread-safe way to lookup items
-// Every lookup, also touches the Item, hence extending it's life
-func (cache *Cache) Get(key string) (interface{}, bool) {
+// get is Get without the middleware
+func (cache *CacheOf[K]) get(key K) (interface{}, bool) {
+	data, _, exists := cache.getWithVersion(key, 0)
+	return data, exists
+}
+
+// GetMany looks up several keys like Get, with a single lock of the cache. Missing keys are left out of the result.
+// It does not load missing keys, see GetManyWithLoader.
+func (cache *CacheOf[K]) GetMany(keys ...K) map[K]interface{} {
+	values := make(map[K]interface{}, len(keys))
+	cache.mutex.Lock()
+	if get := cache.getChain; get != nil {
+		cache.mutex.Unlock()
+		for _, key := range keys {
+			if data, exists := get(key); exists {
+				values[key] = data
+			}
+		}
+		return values
+	}
+	notify := false
+	for _, key := range keys {
+		item, exists, triggerExpirationNotification := cache.GetItem(cache.normalize(key))
+		notify = notify || triggerExpirationNotification
+		cache.metrics.Retrievals++
+		if !exists {
+			cache.metrics.Misses++
+			continue
+		}
+		item.hits++
+		cache.metrics.Hits++
+		cache.refreshIfDue(item)
+		values[key] = item.Data
+	}
+	copier := cache.valueCopier
+	cache.mutex.Unlock()
+	if notify {
+		cache.notifyExpiration()
+	}
+	if copier != nil {
+		for key, data := range values {
+			values[key] = copier(data)
+		}
+	}
+	return values
+}
+
+// GetWithVersion looks up an item like Get and returns its version as well, see SetIfVersion
+func (cache *CacheOf[K]) GetWithVersion(key K) (interface{}, uint64, bool) {
+	return cache.getWithVersion(key, 0)
+}
+
+// GetIfChanged looks up an item like Get, but only returns its value when the version of the item differs from
+// sinceVersion, the version the caller saw last. It returns the current version, which is 0 when the key does not
+// exist, and whether it differs. Pollers of large values skip copying and processing them when nothing changed.
+func (cache *CacheOf[K]) GetIfChanged(key K, sinceVersion uint64) (interface{}, uint64, bool) {
+	data, version, _ := cache.getWithVersion(key, sinceVersion)
+	return data, version, version != sinceVersion
+}
+
+// getWithVersion looks up an item, the value is left out when the version of the item equals unchangedVersion
+func (cache *CacheOf[K]) getWithVersion(key K, unchangedVersion uint64) (interface{}, uint64, bool) {
 	cache.mutex.Lock()
+	key = cache.normalize(key)
 	item, exists, triggerExpirationNotification := cache.GetItem(key)
 
 	var dataToReturn interface{}
+	var version uint64
+	cache.metrics.Retrievals++
 	if exists {
-		dataToReturn = item.Data
+		version = item.version
+		if version != unchangedVersion {
+			dataToReturn = item.Data
+		}
+		item.hits++
+		cache.metrics.Hits++
+		cache.refreshIfDue(item)
+	} else {
+		cache.metrics.Misses++
 	}
+	copier := cache.valueCopier
+	cache.countReadMiss()
 	cache.mutex.Unlock()
 	if triggerExpirationNotification {
-		cache.expirationNotification <- true
+		cache.notifyExpiration()
 	}
-	return dataToReturn, exists
+	if exists && version != unchangedVersion && copier != nil {
+		dataToReturn = copier(dataToReturn)
+	}
+	return dataToReturn, version, exists
+}
+
+// SetIfVersion stores the item with the global TTL only when the version of the key is still the given version, as
+// returned by GetWithVersion, or when the key does not exist and version is 0. It reports whether the item was stored.
+// Together they allow optimistic locking: read a value and its version, compute, and write back unless another
+// write happened in between. Like all conditional writes it bypasses the middleware, see Use.
+func (cache *CacheOf[K]) SetIfVersion(key K, data interface{}, version uint64) bool {
+	return cache.setIfVersion(key, data, ItemExpireWithGlobalTTL, itemOptions[K]{}, version)
 }
 
-func (cache *Cache) GetTTL(key string) (time.Duration, bool) {
+// setIfVersion is SetIfVersion with a TTL and the options of the write
+func (cache *CacheOf[K]) setIfVersion(key K, data interface{}, ttl time.Duration, options itemOptions[K],
+	version uint64) bool {
+	data = cache.lockForSet(data)
+	if cache.currentVersion(key) != version || cache.isShutDown {
+		cache.mutex.Unlock()
+		return false
+	}
+	key, isNew := cache.set(key, data, ttl, options)
+	newItemCallback := cache.newItemCallback
+	cache.mutex.Unlock()
+
+	if isNew && newItemCallback != nil {
+		newItemCallback(key, data)
+	}
+	cache.notifyExpiration()
+	return true
+}
+
+// currentVersion returns the version of the key, 0 when it is missing or expired. The cache must be locked.
+func (cache *CacheOf[K]) currentVersion(key K) uint64 {
+	if item, exists := cache.items.Get(cache.normalize(key)); exists && !item.expired() {
+		return item.version
+	}
+	return 0
+}
+
+// CompareAndSwap stores the new value, with the global TTL, only when the key holds a value equal to old, and reports
+// whether it did, so concurrent writers can update a value without an external lock. Values are compared with the
+// function set with SetEqualFunc, by default reflect.DeepEqual. A missing key never matches. It bypasses the
+// middleware, see Use.
+func (cache *CacheOf[K]) CompareAndSwap(key K, old, new interface{}) bool {
+	new = cache.lockForSet(new)
+	item, exists := cache.items.Get(cache.normalize(key))
+	equal := cache.equal
+	if equal == nil {
+		equal = reflect.DeepEqual
+	}
+	if !exists || item.expired() || cache.isShutDown || !equal(item.Data, old) {
+		cache.mutex.Unlock()
+		return false
+	}
+	key, isNew := cache.set(key, new, ItemExpireWithGlobalTTL, itemOptions[K]{})
+	newItemCallback := cache.newItemCallback
+	cache.mutex.Unlock()
+	if isNew && newItemCallback != nil {
+		newItemCallback(key, new)
+	}
+	cache.notifyExpiration()
+	return true
+}
+
+// Replace stores the item only when the key exists and is not expired, and reports whether it did, so a write does not
+// bring back an item which expired or was removed in the meantime. It bypasses the middleware, see Use.
+func (cache *CacheOf[K]) Replace(key K, data interface{}, ttl time.Duration) bool {
+	data = cache.lockForSet(data)
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() || cache.isShutDown {
+		cache.mutex.Unlock()
+		return false
+	}
+	cache.set(key, data, ttl, itemOptions[K]{})
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+	return true
+}
+
+// Swap stores the item and returns the value it replaced under a single lock, existed is false when the key was
+// missing or expired. It bypasses the middleware, see Use.
+func (cache *CacheOf[K]) Swap(key K, data interface{}, ttl time.Duration) (previous interface{}, existed bool) {
+	data = cache.lockForSet(data)
+	if item, exists := cache.items.Get(cache.normalize(key)); exists && !item.expired() {
+		previous, existed = item.Data, true
+	}
+	key, isNew := cache.set(key, data, ttl, itemOptions[K]{})
+	newItemCallback := cache.newItemCallback
+	copier := cache.valueCopier
+	cache.mutex.Unlock()
+	if isNew && newItemCallback != nil {
+		newItemCallback(key, data)
+	}
+	cache.notifyExpiration()
+	if existed && copier != nil {
+		previous = copier(previous)
+	}
+	return previous, existed
+}
+
+// SetEqualFunc sets how CompareAndSwap compares values, for instance by a version field, nil restores
+// reflect.DeepEqual. The function is called with the cache locked, so it must not use the cache.
+func (cache *CacheOf[K]) SetEqualFunc(equal func(a, b interface{}) bool) {
 	cache.mutex.Lock()
-	item, exists, _ := cache.GetItem(key)
+	cache.equal = equal
+	cache.mutex.Unlock()
+}
+
+// GetValidator returns the validator stored with SetWithValidator, without touching the item or counting it in the
+// metrics. It is empty when the item was stored without one.
+func (cache *CacheOf[K]) GetValidator(key K) (string, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		return "", false
+	}
+	return item.validator, true
+}
+
+// Has reports whether the key exists and is not expired, without extending its TTL or counting it in the metrics.
+// It never loads the key or calls callbacks.
+func (cache *CacheOf[K]) Has(key K) bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	return exists && !item.expired()
+}
+
+// GetItemMeta returns a copy of the item and its metadata without touching it or counting it in the metrics
+func (cache *CacheOf[K]) GetItemMeta(key K) (ItemMetaOf[K], bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		return ItemMetaOf[K]{}, false
+	}
+	meta := ItemMetaOf[K]{Key: item.key, Value: item.Data, TTL: item.TTL, CreatedAt: item.createdAt, Hits: item.hitCount(),
+		Version: item.version, Validator: item.validator, Cost: item.weight}
+	if item.expires() {
+		meta.ExpireAt = item.ExpireAt
+	}
+	if item.TTL < 0 || (item.TTL == 0 && cache.ttl == 0) {
+		meta.TTL = ItemNotExpire
+	}
+	return meta, true
+}
+
+// Update replaces the value of a key with the value returned by fn, which receives the current value and whether the
+// key exists, and stores it with the returned TTL. It reports whether the key existed. fn runs with the cache locked,
+// so no other write can happen in between, and must not use the cache itself. The middleware does not see the write,
+// see Use.
+func (cache *CacheOf[K]) Update(key K, fn func(data interface{}, exists bool) (interface{}, time.Duration)) bool {
+	cache.mutex.Lock()
+	if cache.isShutDown {
+		cache.mutex.Unlock()
+		return false
+	}
+	var data interface{}
+	item, exists := cache.items.Get(cache.normalize(key))
+	exists = exists && !item.expired()
+	if exists {
+		data = item.Data
+	}
+	data, ttl := fn(data, exists)
+	key, isNew := cache.set(key, data, ttl, itemOptions[K]{})
+	newItemCallback := cache.newItemCallback
+	cache.mutex.Unlock()
+
+	if isNew && newItemCallback != nil {
+		newItemCallback(key, data)
+	}
+	cache.notifyExpiration()
+	return exists
+}
+
+// GetOrSetFunc returns the value of the key, or stores and returns the value and TTL returned by fn when the key is
+// missing. Concurrent calls for the same missing key wait for a single call of fn. It reports whether the value was
+// already cached.
+func (cache *CacheOf[K]) GetOrSetFunc(key K, fn func() (interface{}, time.Duration)) (interface{}, bool) {
+	if data, exists := cache.lookup(key); exists {
+		return data, true
+	}
+	cache.LockKey(key)
+	defer cache.UnlockKey(key)
+	if data, exists := cache.lookup(key); exists {
+		return data, true
+	}
+	data, ttl := fn()
+	cache.SetWithTTL(key, data, ttl)
+	return data, false
+}
+
+// GetOrSet returns the value of the key and true, or stores the given value with an individual TTL and returns it and
+// false when the key is missing. The lookup and the write happen under a single lock, so of concurrent calls for a
+// missing key exactly one stores its value. It bypasses the middleware, see Use.
+func (cache *CacheOf[K]) GetOrSet(key K, data interface{}, ttl time.Duration) (actual interface{}, loaded bool) {
+	data = cache.lockForSet(data)
+	copier := cache.valueCopier
+	key = cache.normalize(key)
+	cache.metrics.Retrievals++
+	item, exists, triggerExpirationNotification := cache.GetItem(key)
+	if exists {
+		item.hits++
+		cache.metrics.Hits++
+		actual = item.Data
+		cache.mutex.Unlock()
+		if triggerExpirationNotification {
+			cache.notifyExpiration()
+		}
+		if copier != nil {
+			actual = copier(actual)
+		}
+		return actual, true
+	}
+	cache.metrics.Misses++
+	key, isNew := cache.set(key, data, ttl, itemOptions[K]{})
+	newItemCallback := cache.newItemCallback
+	cache.mutex.Unlock()
+
+	if isNew && newItemCallback != nil {
+		newItemCallback(key, data)
+	}
+	cache.notifyExpiration()
+	return data, false
+}
+
+func (cache *CacheOf[K]) GetTTL(key K) (time.Duration, bool) {
+	cache.mutex.Lock()
+	item, exists, _ := cache.GetItem(cache.normalize(key))
 	cache.mutex.Unlock()
 
 	if exists {
@@ -210,81 +862,626 @@ func (cache *Cache) GetTTL(key string) (time.Duration, bool) {
 	}
 }
 
-func (cache *Cache) Remove(key string) bool {
+// Remove removes the key and wakes the expiration goroutine, so it does not wait for an item that is gone
+func (cache *CacheOf[K]) Remove(key K) bool {
 	cache.mutex.Lock()
-	object, exists := cache.items[key]
-	if !exists {
+	removed := cache.remove(key)
+	cache.mutex.Unlock()
+	if removed {
+		cache.notifyExpiration()
+	}
+	return removed
+}
+
+// Pop returns the value of the key and removes it under a single lock, so of concurrent calls only one gets the
+// value, for one-shot tokens and dedup windows. It counts in the metrics like Get, but bypasses the middleware, see
+// Use.
+func (cache *CacheOf[K]) Pop(key K) (interface{}, bool) {
+	cache.mutex.Lock()
+	cache.metrics.Retrievals++
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		cache.metrics.Misses++
 		cache.mutex.Unlock()
-		return false
+		return nil, false
+	}
+	cache.metrics.Hits++
+	cache.deleteItem(item, EventRemoved)
+	copier := cache.valueCopier
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+	if copier != nil {
+		return copier(item.Data), true
+	}
+	return item.Data, true
+}
+
+// RemoveIf removes the key only when it is stored, not expired, and the predicate returns true for its value, and
+// reports whether it was removed. The check and the removal happen under a single lock, for invalidate-if-unchanged
+// patterns. The predicate runs with the cache locked and must not use the cache.
+func (cache *CacheOf[K]) RemoveIf(key K, predicate func(value interface{}) bool) bool {
+	cache.mutex.Lock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	removed := exists && !item.expired() && predicate(item.Data)
+	if removed {
+		cache.deleteItem(item, EventRemoved)
 	}
-	delete(cache.items, object.key)
-	cache.priorityQueue.remove(object)
 	cache.mutex.Unlock()
+	if removed {
+		cache.notifyExpiration()
+	}
+	return removed
+}
 
+// RemoveMany removes the keys under a single lock of the cache and returns how many of them were stored
+func (cache *CacheOf[K]) RemoveMany(keys ...K) int {
+	cache.mutex.Lock()
+	removed := 0
+	for _, key := range keys {
+		if cache.remove(key) {
+			removed++
+		}
+	}
+	cache.compactIfShrunk()
+	cache.mutex.Unlock()
+	if removed > 0 {
+		cache.notifyExpiration()
+	}
+	return removed
+}
+
+// RemoveFunc removes the items which are not expired and for which the predicate returns true, and returns how many
+// there were. The predicate runs with the cache locked and must not use the cache.
+func (cache *CacheOf[K]) RemoveFunc(predicate func(key K, value interface{}) bool) int {
+	cache.mutex.Lock()
+	removed := cache.removeFunc(func(key K, item *ItemOf[K]) bool {
+		return !item.expired() && predicate(key, item.Data)
+	})
+	cache.mutex.Unlock()
+	if removed > 0 {
+		cache.notifyExpiration()
+	}
+	return removed
+}
+
+// remove is Remove with the cache mutex held
+func (cache *CacheOf[K]) remove(key K) bool {
+	key = cache.normalize(key)
+	object, exists := cache.items.Get(key)
+	if !exists {
+		// a value kept after its expiration must not outlive an explicit removal either
+		delete(cache.staleValues, key)
+		return false
+	}
+	cache.deleteItem(object, EventRemoved)
 	return true
 }
 
+// itemObserver is told about changes of the items in the map, with the cache mutex held
+type itemObserver[K comparable] interface {
+	itemAdded(item *ItemOf[K])
+	// itemUpdated is told the previous value and weight of the item
+	itemUpdated(item *ItemOf[K], oldData interface{}, oldWeight int64)
+	// itemRemoved is told whether the cache evicted the item by itself, because it expired or did not fit
+	itemRemoved(item *ItemOf[K], evicted bool)
+}
+
+// runCallback runs an expiration or removal callback in its own goroutine, the cache mutex must be held
+func (cache *CacheOf[K]) runCallback(callback func()) {
+	cache.runningCallbacks++
+	go func() {
+		defer func() {
+			cache.mutex.Lock()
+			cache.runningCallbacks--
+			if cache.runningCallbacks == 0 {
+				cache.callbacksDone.Broadcast()
+			}
+			cache.mutex.Unlock()
+		}()
+		callback()
+	}()
+}
+
+// Drain waits until the expiration and removal callbacks started so far have returned, including the callbacks
+// they cause themselves. Close drains the cache as well, so callbacks must not call Close.
+func (cache *CacheOf[K]) Drain() {
+	cache.mutex.Lock()
+	for cache.runningCallbacks > 0 {
+		cache.callbacksDone.Wait()
+	}
+	cache.mutex.Unlock()
+}
+
+// nextVersion returns a new item version, the cache mutex must be held. Versions come from one counter,
+// so a key that is removed and added again does not reuse a version.
+func (cache *CacheOf[K]) nextVersion() uint64 {
+	cache.lastVersion++
+	return cache.lastVersion
+}
+
+// notifyExpiration wakes the expiration goroutine to reschedule without waiting for it. The channel buffers one
+// notification, so a notification sent while the goroutine computes its next wakeup is seen before it goes to sleep,
+// and notifications sent while one is pending are merged into it.
+func (cache *CacheOf[K]) notifyExpiration() {
+	select {
+	case cache.expirationNotification <- true:
+	default:
+	}
+}
+
+// IsClosed reports whether Close was called
+func (cache *CacheOf[K]) IsClosed() bool {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.isShutDown
+}
+
+// insertItem adds a new item to the map and the queue, the cache mutex must be held
+func (cache *CacheOf[K]) insertItem(item *ItemOf[K]) {
+	item.lock = &cache.mutex
+	item.clock = cache.clock
+	delete(cache.staleValues, item.key)
+	cache.items.Set(item.key, item)
+	cache.invalidateReads()
+	if cache.items.Len() > cache.peakItems {
+		cache.peakItems = cache.items.Len()
+	}
+	cache.priorityQueue.push(item)
+	cache.addToScan(item)
+	if cache.observer != nil {
+		cache.observer.itemAdded(item)
+	}
+}
+
+// deleteItem removes an item from the map and the queue, publishes the event of the reason and calls the removal
+// callback, the cache mutex must be held
+func (cache *CacheOf[K]) deleteItem(item *ItemOf[K], reason EventType) {
+	cache.items.Delete(item.key)
+	if reason == EventRemoved || reason == EventPurged {
+		delete(cache.staleValues, item.key)
+	}
+	cache.invalidateReads()
+	cache.priorityQueue.remove(item, reason == EventExpired || reason == EventEvicted)
+	cache.removeFromScan()
+	cache.totalCost -= item.weight
+	if cache.observer != nil {
+		cache.observer.itemRemoved(item, reason != EventRemoved)
+	}
+	cache.publish(reason, item.key, item.Data)
+	if reason == EventExpired {
+		// before untag, the stale value keeps the tags for InvalidateTag
+		cache.keepStale(item)
+	}
+	cache.untag(item)
+	if cache.removalCallback != nil {
+		removalCallback := cache.removalCallback
+		cache.runCallback(func() { removalCallback(item.key, item.Data, reason) })
+	}
+}
+
+// normalize applies the key normalizer, the cache mutex must be held
+func (cache *CacheOf[K]) normalize(key K) K {
+	if cache.keyNormalizer == nil {
+		return key
+	}
+	return cache.keyNormalizer(key)
+}
+
+// touch resets the expiration time of the item, shortened by a random part of its TTL when jitter is configured
+func (cache *CacheOf[K]) touch(item *ItemOf[K]) {
+	cache.invalidateReads()
+	item.touch()
+	if cache.ttlJitter > 0 && item.TTL > 0 {
+		item.ExpireAt = item.ExpireAt.Add(-time.Duration(rand.Float64() * cache.ttlJitter * float64(item.TTL)))
+	}
+	item.capLifetime()
+}
+
+// evictForSize evicts the items closest to their expiration until there is room for the given number of new items
+// within the size limit, the cache mutex must be held
+func (cache *CacheOf[K]) evictForSize(room int) {
+	if cache.sizeLimit <= 0 {
+		return
+	}
+	for cache.items.Len() > 0 && cache.items.Len()+room > cache.sizeLimit {
+		cache.evict(cache.evictionCandidate(nil))
+	}
+}
+
+// evictForCost evicts the items closest to their expiration until their total cost is within the cost limit,
+// except for the item that is being written, the cache mutex must be held
+func (cache *CacheOf[K]) evictForCost(keep *ItemOf[K]) {
+	if cache.maxCost <= 0 {
+		return
+	}
+	for cache.totalCost > cache.maxCost {
+		candidate := cache.evictionCandidate(keep)
+		if candidate == nil {
+			return
+		}
+		cache.evict(candidate)
+	}
+}
+
+// evict removes an item to respect the limits of the cache, the cache mutex must be held
+func (cache *CacheOf[K]) evict(item *ItemOf[K]) {
+	cache.deleteItem(item, EventEvicted)
+	cache.metrics.Evicted++
+	if cache.expireCallback != nil {
+		expireCallback := cache.expireCallback
+		cache.runCallback(func() { expireCallback(item.key, item.Data) })
+	}
+}
+
+// evictionCandidate returns the item closest to its expiration, or any item of a cache without expiration, other than
+// the given item. It is nil when there is no other item.
+func (cache *CacheOf[K]) evictionCandidate(except *ItemOf[K]) *ItemOf[K] {
+	if queue := cache.priorityQueue; queue.Len() > 0 {
+		if queue.items[0] != except {
+			return queue.items[0]
+		}
+		// the next one in the heap order is one of the children of the root
+		switch {
+		case queue.Len() > 2 && queue.Less(2, 1):
+			return queue.items[2]
+		case queue.Len() > 1:
+			return queue.items[1]
+		}
+		return nil
+	}
+	var candidate *ItemOf[K]
+	cache.items.Range(func(_ K, item *ItemOf[K]) bool {
+		if item == except {
+			return true
+		}
+		candidate = item
+		return false
+	})
+	return candidate
+}
+
+// removeFunc removes all items the predicate holds for and returns how many there were,
+// the cache mutex must be held
+func (cache *CacheOf[K]) removeFunc(predicate func(key K, item *ItemOf[K]) bool) int {
+	removed := 0
+	for _, item := range cache.storedItems() {
+		if predicate(item.key, item) {
+			cache.deleteItem(item, EventRemoved)
+			removed++
+		}
+	}
+	cache.dropStaleFunc(predicate)
+	cache.compactIfShrunk()
+	return removed
+}
+
+// peek looks up an Item without touching it, the expiration time is zero for items that do not expire
+func (cache *CacheOf[K]) peek(key K) (interface{}, time.Time, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		return nil, time.Time{}, false
+	}
+	if !item.expires() {
+		return item.Data, time.Time{}, true
+	}
+	return item.Data, item.ExpireAt, true
+}
+
+// Peek looks up an item without extending its TTL, changing its position in the expiration order, or counting the
+// lookup in the metrics, regardless of SkipTtlExtensionOnHit. The value is copied with the value copier, when one is
+// set.
+func (cache *CacheOf[K]) Peek(key K) (interface{}, bool) {
+	data, _, exists := cache.peek(key)
+	if exists {
+		data = cache.copyValue(data)
+	}
+	return data, exists
+}
+
+// Keys returns a snapshot of the keys of all items which are not expired, in no particular order
+func (cache *CacheOf[K]) Keys() []K {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	keys := make([]K, 0, cache.items.Len())
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		if !item.expired() {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	return keys
+}
+
+// Items returns a consistent snapshot of the keys and values of all items which are not expired, taken under a single
+// lock of the cache. The values are copied with the value copier, when one is set.
+func (cache *CacheOf[K]) Items() map[K]interface{} {
+	cache.mutex.Lock()
+	items := make(map[K]interface{}, cache.items.Len())
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		if !item.expired() {
+			items[key] = item.Data
+		}
+		return true
+	})
+	copier := cache.valueCopier
+	cache.mutex.Unlock()
+	if copier != nil {
+		for key, data := range items {
+			items[key] = copier(data)
+		}
+	}
+	return items
+}
+
+// Range calls f for the items which are not expired, in no particular order, until f returns false. Unlike Items it
+// copies nothing up front, so the cache stays locked for the whole iteration: f must be quick and must not use the
+// cache. Values are copied with the value copier, when one is set.
+func (cache *CacheOf[K]) Range(f func(key K, value interface{}) bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	copier := cache.valueCopier
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		if item.expired() {
+			return true
+		}
+		if copier != nil {
+			return f(key, copier(item.Data))
+		}
+		return f(key, item.Data)
+	})
+}
+
 // Count returns the number of items in the cache
-func (cache *Cache) Count() int {
+func (cache *CacheOf[K]) Count() int {
 	cache.mutex.Lock()
-	length := len(cache.items)
+	length := cache.items.Len()
 	cache.mutex.Unlock()
 	return length
 }
 
-func (cache *Cache) SetTTL(ttl time.Duration) {
+// CountFunc returns the number of items which are not expired and for which the predicate returns true.
+// The predicate runs with the cache locked and must not use the cache.
+func (cache *CacheOf[K]) CountFunc(predicate func(key K, value interface{}) bool) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	count := 0
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		if !item.expired() && predicate(key, item.Data) {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+func (cache *CacheOf[K]) SetTTL(ttl time.Duration) {
 	cache.mutex.Lock()
 	cache.ttl = ttl
+	cache.invalidateReads()
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+}
+
+// SetCacheSizeLimit limits the number of items in the cache, zero means no limit. When the cache is full, adding
+// a new key evicts the Item closest to its expiration.
+func (cache *CacheOf[K]) SetCacheSizeLimit(limit int) {
+	cache.mutex.Lock()
+	cache.sizeLimit = limit
+	cache.evictForSize(0)
+	cache.mutex.Unlock()
+}
+
+// SetWeigher makes the items count against a cost limit, such as a budget of bytes, instead of only their number.
+// The weigher returns the cost of a value, costs reported by a ResultLoaderFunc take precedence. When the total cost
+// exceeds maxCost the items closest to their expiration are evicted, zero means no limit. Items which cost more than
+// maxCost on their own are not stored, and an existing value of their key is evicted.
+func (cache *CacheOf[K]) SetWeigher(weigher func(key K, value interface{}) int64, maxCost int64) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.weigher = weigher
+	cache.maxCost = maxCost
+	cache.totalCost = 0
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		if !item.costReported {
+			item.weight = 0
+			if weigher != nil {
+				item.weight = weigher(key, item.Data)
+			}
+		}
+		cache.totalCost += item.weight
+		return true
+	})
+	cache.evictForCost(nil)
+}
+
+// SetTTLJitter shortens the TTL of every Item by a random part of at most fraction, between 0 and 1, so items
+// stored at the same moment do not all expire at once and cause a stampede on the backing store.
+func (cache *CacheOf[K]) SetTTLJitter(fraction float64) {
+	cache.mutex.Lock()
+	cache.ttlJitter = fraction
 	cache.mutex.Unlock()
-	cache.expirationNotification <- true
 }
 
-// SetExpirationCallback sets a callback that will be called when an Item expires
-func (cache *Cache) SetExpirationCallback(callback expireCallback) {
+// SetValueCopier sets a function returning a deep copy of a value. Get returns copies made with it, so callers can
+// modify the slices and maps they get without racing each other. With copyOnSet the value is copied on Set as well,
+// so the caller of Set can keep modifying its own value. The copier runs without holding the cache lock.
+func (cache *CacheOf[K]) SetValueCopier(copier func(value interface{}) interface{}, copyOnSet bool) {
+	cache.mutex.Lock()
+	cache.valueCopier = copier
+	cache.copyOnSet = copyOnSet
+	cache.invalidateReads()
+	cache.mutex.Unlock()
+}
+
+// SetKeyNormalizer sets a function applied to the key of every operation, so keys that only differ in a way the
+// normalizer removes, like FoldKeyCase does with case, refer to the same Item. Set it before adding items.
+func (cache *CacheOf[K]) SetKeyNormalizer(normalizer func(key K) K) {
+	cache.mutex.Lock()
+	cache.keyNormalizer = normalizer
+	cache.invalidateReads()
+	cache.mutex.Unlock()
+}
+
+// FoldKeyCase is a key normalizer for case insensitive keys, such as HTTP header names or email addresses
+func FoldKeyCase(key string) string {
+	return strings.ToLower(key)
+}
+
+// SetExpirationCallback sets a callback that will be called when an Item expires, or is evicted by the size limit
+func (cache *CacheOf[K]) SetExpirationCallback(callback expireCallback[K]) {
 	cache.expireCallback = callback
 }
 
+// SetRemovalCallback sets a callback that will be called whenever an Item leaves the cache, with the reason:
+// EventExpired, EventEvicted for the size limit, EventPurged for Purge, or EventRemoved for Remove and the other
+// explicit removals
+func (cache *CacheOf[K]) SetRemovalCallback(callback func(key K, value interface{}, reason EventType)) {
+	cache.mutex.Lock()
+	cache.removalCallback = callback
+	cache.mutex.Unlock()
+}
+
 // SetCheckExpirationCallback sets a callback that will be called when an Item is about to expire
 // in order to allow external code to decide whether the Item expires or remains for another TTL cycle
-func (cache *Cache) SetCheckExpirationCallback(callback checkExpireCallback) {
+func (cache *CacheOf[K]) SetCheckExpirationCallback(callback checkExpireCallback[K]) {
 	cache.checkExpireCallback = callback
 }
 
 // SetNewItemCallback sets a callback that will be called when a new Item is added to the cache
-func (cache *Cache) SetNewItemCallback(callback expireCallback) {
+func (cache *CacheOf[K]) SetNewItemCallback(callback expireCallback[K]) {
 	cache.newItemCallback = callback
 }
 
 // SkipTtlExtensionOnHit allows the user to change the cache behaviour. When this flag is set to true it will
 // no longer extend TTL of items when they are retrieved using Get, or when their expiration condition is evaluated
-// using SetCheckExpirationCallback.
-func (cache *Cache) SkipTtlExtensionOnHit(value bool) {
+// using SetCheckExpirationCallback. Items written with WithTouchOnHit or WithNoTouchOnHit ignore this flag.
+func (cache *CacheOf[K]) SkipTtlExtensionOnHit(value bool) {
+	cache.mutex.Lock()
 	cache.skipTTLExtension = value
+	cache.invalidateReads()
+	cache.mutex.Unlock()
+}
+
+// extendsOnHit reports whether hits extend the TTL of the item, the cache mutex must be held
+func (cache *CacheOf[K]) extendsOnHit(item *ItemOf[K]) bool {
+	if item.touchOnHit != touchDefault {
+		return item.touchOnHit == touchAlways
+	}
+	return !cache.skipTTLExtension
+}
+
+// SetMaxLifetime limits how long items live after they were first stored, however often hits or writes extend their
+// TTL, so the TTL acts as an idle timeout and the lifetime as an absolute limit, like the sessions of many web
+// frameworks. Items without a TTL expire at the end of it too. WithMaxLifetime sets it per item. Zero means no limit.
+// It applies to items stored afterwards.
+func (cache *CacheOf[K]) SetMaxLifetime(lifetime time.Duration) {
+	cache.mutex.Lock()
+	cache.maxLifetime = lifetime
+	cache.mutex.Unlock()
 }
 
-// Purge will remove all entries
-func (cache *Cache) Purge() {
+// SetMaxTTLExtension limits how far hits extend the life of an item: at most by extension beyond the expiration time
+// the item got when it was written. This is a middle ground between the sliding TTL of the default and the fixed TTL
+// of SkipTtlExtensionOnHit, for leases. Zero means no limit.
+func (cache *CacheOf[K]) SetMaxTTLExtension(extension time.Duration) {
 	cache.mutex.Lock()
-	cache.items = make(map[string]*Item)
-	cache.priorityQueue = newPriorityQueue()
+	cache.maxTTLExtension = extension
+	cache.mutex.Unlock()
+}
+
+// Purge will remove all entries. Subscriptions get an EventPurged for every entry, and the removal callback is called
+// for all of them from a single goroutine.
+func (cache *CacheOf[K]) Purge() {
+	cache.mutex.Lock()
+	purged := cache.storedItems()
+	for _, item := range purged {
+		if cache.observer != nil {
+			cache.observer.itemRemoved(item, false)
+		}
+		cache.publish(EventPurged, item.key, item.Data)
+		cache.items.Delete(item.key)
+	}
+	if _, isMap := cache.items.(mapStore[K]); isMap {
+		// an emptied map keeps the memory of its peak size, and peakItems is reset, so compaction would not free it
+		cache.items = newMapStore[K](0)
+	}
+	if cache.removalCallback != nil && len(purged) > 0 {
+		removalCallback := cache.removalCallback
+		cache.runCallback(func() {
+			for _, item := range purged {
+				removalCallback(item.key, item.Data, EventPurged)
+			}
+		})
+	}
+	cache.peakItems = 0
+	cache.scanItems, cache.scanRemoved = nil, 0
+	cache.staleValues, cache.staleOrder = nil, nil
+	cache.tags = nil
+	cache.totalCost = 0
+	cache.invalidateReads()
+	if cache.priorityQueue != nil {
+		stats := cache.priorityQueue.stats
+		cache.priorityQueue = newPriorityQueue[K]()
+		cache.priorityQueue.stats = stats
+	}
 	cache.mutex.Unlock()
 }
 
 // NewCache is a helper to create instance of the Cache struct
 func NewCache() *Cache {
+	cache := &Cache{}
+	cache.init(true)
+	return cache
+}
 
-	shutdownChan := make(chan chan struct{})
+// NewMapCache creates a Cache without expiration: TTLs are ignored, and there is no expiration queue and goroutine
+// to pay for. The rest of the API, including callbacks, events and the size limit, which evicts arbitrary items,
+// works as usual.
+func NewMapCache() *Cache {
+	cache := &Cache{}
+	cache.init(false)
+	return cache
+}
+
+// NewCacheOf creates a cache with keys of type K
+func NewCacheOf[K comparable]() *CacheOf[K] {
+	cache := &CacheOf[K]{}
+	cache.init(true)
+	return cache
+}
+
+// NewMapCacheOf creates a CacheOf without expiration, see NewMapCache
+func NewMapCacheOf[K comparable]() *CacheOf[K] {
+	cache := &CacheOf[K]{}
+	cache.init(false)
+	return cache
+}
+
+// Uint64Cache is a cache keyed by numeric ids, which saves formatting every id as a string
+type Uint64Cache = CacheOf[uint64]
 
-	cache := &Cache{
-		items:                  make(map[string]*Item),
-		priorityQueue:          newPriorityQueue(),
-		expirationNotification: make(chan bool),
-		expirationTime:         time.Now(),
-		shutdownSignal:         shutdownChan,
-		isShutDown:             false,
+// NewUint64Cache creates a cache keyed by numeric ids
+func NewUint64Cache() *Uint64Cache {
+	return NewCacheOf[uint64]()
+}
+
+// init prepares a zero cache and starts its expiration goroutine, unless expiration is disabled
+func (cache *CacheOf[K]) init(expiration bool) {
+	cache.items = newMapStore[K](0)
+	cache.clock = realClock{}
+	cache.expirationNotification = make(chan bool, 1)
+	cache.expirationTime = cache.clock.Now()
+	cache.shutdownSignal = make(chan chan struct{})
+	cache.done = make(chan struct{})
+	cache.callbacksDone = sync.NewCond(&cache.mutex)
+	if !expiration {
+		cache.expirationDisabled = true
+		return
 	}
+	cache.priorityQueue = newPriorityQueue[K]()
 	go cache.startExpirationProcessing()
-	return cache
 }
 
 func min(duration time.Duration, second time.Duration) time.Duration {
@@ -8,7 +8,10 @@ import (
 	"go.uber.org/goleak"
 
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -465,3 +468,880 @@ func TestCache_Purge(t *testing.T) {
 	}
 
 }
+
+func TestCache_SetValueCopier(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	copies := 0
+	cache.SetValueCopier(func(value interface{}) interface{} {
+		copies++
+		return append([]int(nil), value.([]int)...)
+	}, true)
+
+	original := []int{1, 2, 3}
+	cache.Set("key", original)
+	original[0] = 42
+
+	data, _ := cache.Get("key")
+	assert.Equal(t, []int{1, 2, 3}, data, "Expected the value to be copied on Set")
+	data.([]int)[1] = 42
+	data, _ = cache.Get("key")
+	assert.Equal(t, []int{1, 2, 3}, data, "Expected the value to be copied on Get")
+	assert.Equal(t, 3, copies)
+
+	_, exists := cache.Get("missing")
+	assert.False(t, exists)
+	assert.Equal(t, 3, copies, "Expected no copy on a miss")
+}
+
+func TestCacheOf_ComparableKeys(t *testing.T) {
+	type objectKey struct {
+		tenant string
+		id     int
+	}
+	cache := NewCacheOf[objectKey]()
+	defer cache.Close()
+
+	expired := make(chan objectKey, 1)
+	cache.SetExpirationCallback(func(key objectKey, value interface{}) {
+		expired <- key
+	})
+	subscription := cache.Subscribe(10)
+	defer subscription.Close()
+
+	cache.SetWithTTL(objectKey{"a", 1}, "one", 10*time.Millisecond)
+	cache.Set(objectKey{"b", 1}, "other tenant")
+	data, exists := cache.Get(objectKey{"a", 1})
+	assert.True(t, exists)
+	assert.Equal(t, "one", data)
+	_, exists = cache.Get(objectKey{"a", 2})
+	assert.False(t, exists)
+
+	select {
+	case key := <-expired:
+		assert.Equal(t, objectKey{"a", 1}, key)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the item to expire")
+	}
+	event := <-subscription.C
+	assert.Equal(t, EventInserted, event.Type)
+	assert.Equal(t, objectKey{"a", 1}, event.Key)
+	assert.Equal(t, 1, cache.Count())
+}
+
+func TestUint64Cache_GetDoesNotAllocate(t *testing.T) {
+	cache := NewUint64Cache()
+	defer cache.Close()
+
+	value := new(int)
+	cache.SetWithTTL(42, value, time.Minute)
+	allocs := testing.AllocsPerRun(100, func() {
+		data, _ := cache.Get(42)
+		if data != value {
+			t.Fatal("Expected the stored value")
+		}
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestCache_SetKeyNormalizer(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetKeyNormalizer(FoldKeyCase)
+
+	cache.Set("Content-Type", "text/html")
+	cache.Set("content-type", "application/json")
+	assert.Equal(t, 1, cache.Count())
+	data, exists := cache.Get("CONTENT-TYPE")
+	assert.True(t, exists)
+	assert.Equal(t, "application/json", data)
+	_, exists = cache.GetTTL("Content-type")
+	assert.True(t, exists)
+	assert.True(t, cache.Remove("Content-Type"))
+	assert.Equal(t, 0, cache.Count())
+
+	namespace := cache.Namespace("Users")
+	namespace.Set("Alice@Example.com", 1)
+	_, exists = namespace.Get("alice@example.com")
+	assert.True(t, exists)
+	assert.Equal(t, 1, namespace.Count())
+}
+
+func TestCache_Update(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	increment := func(data interface{}, exists bool) (interface{}, time.Duration) {
+		if !exists {
+			return 1, time.Minute
+		}
+		return data.(int) + 1, time.Minute
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Update("counter", increment)
+		}()
+	}
+	wg.Wait()
+	data, _ := cache.Get("counter")
+	assert.Equal(t, 100, data)
+	assert.True(t, cache.Update("counter", increment))
+	assert.False(t, cache.Update("new", increment))
+}
+
+func TestCache_GetOrSetFunc(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var calls int32
+	load := func() (interface{}, time.Duration) {
+		atomic.AddInt32(&calls, 1)
+		<-time.After(10 * time.Millisecond)
+		return "loaded", time.Minute
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, _ := cache.GetOrSetFunc("key", load)
+			assert.Equal(t, "loaded", data)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	_, cached := cache.GetOrSetFunc("key", load)
+	assert.True(t, cached)
+	ttl, _ := cache.GetTTL("key")
+	assert.Equal(t, time.Minute, ttl)
+}
+
+func TestCache_GetOrSet(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var stored int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actual, loaded := cache.GetOrSet("key", i, time.Minute)
+			if !loaded {
+				atomic.AddInt32(&stored, 1)
+				assert.Equal(t, i, actual)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stored), "Expected exactly one call to store its value")
+
+	first, _ := cache.Get("key")
+	actual, loaded := cache.GetOrSet("key", "other", time.Second)
+	assert.True(t, loaded)
+	assert.Equal(t, first, actual)
+	ttl, _ := cache.GetTTL("key")
+	assert.Equal(t, time.Minute, ttl)
+}
+
+func TestCache_GetMany(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.SetWithTTL("b", 2, time.Minute)
+	cache.SetWithTTL("expired", 3, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+
+	values := cache.GetMany("a", "b", "expired", "missing")
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": 2}, values)
+	assert.Empty(t, cache.GetMany())
+	metrics := cache.GetMetrics()
+	assert.Equal(t, int64(4), metrics.Retrievals)
+	assert.Equal(t, int64(2), metrics.Hits)
+	assert.Equal(t, int64(2), metrics.Misses)
+}
+
+func TestCache_SetMany(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	var added int32
+	cache.SetNewItemCallback(func(key string, value interface{}) {
+		atomic.AddInt32(&added, 1)
+	})
+	cache.SetWithTTL("existing", 0, time.Hour)
+
+	items := make(map[string]interface{})
+	for i := 0; i < 100; i++ {
+		items[fmt.Sprintf("key_%d", i)] = i
+	}
+	items["existing"] = -1
+	cache.SetMany(items, time.Duration(50)*time.Millisecond)
+	assert.Equal(t, 101, cache.Count())
+	assert.Equal(t, int32(101), atomic.LoadInt32(&added), "Expected the new item callback for new keys only")
+	data, _ := cache.Get("existing")
+	assert.Equal(t, -1, data)
+	assert.NoError(t, cache.VerifyIntegrity())
+
+	<-time.After(100 * time.Millisecond)
+	assert.Equal(t, 0, cache.Count(), "Expected the items to expire in order")
+	assert.Equal(t, int64(101), cache.GetMetrics().QueuePushes)
+
+	cache.SetCacheSizeLimit(10)
+	cache.SetMany(items, time.Minute)
+	assert.Equal(t, 10, cache.Count(), "Expected the size limit to hold")
+	assert.NoError(t, cache.VerifyIntegrity())
+}
+
+func TestCache_RemoveMany(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+
+	assert.Equal(t, 2, cache.RemoveMany("a", "b", "missing"))
+	assert.Equal(t, 0, cache.RemoveMany())
+	assert.Equal(t, 1, cache.Count())
+	assert.True(t, cache.Has("c"))
+}
+
+func TestCache_Peek(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("key", "value", 100*time.Millisecond)
+	meta, _ := cache.GetItemMeta("key")
+
+	<-time.After(50 * time.Millisecond)
+	data, exists := cache.Peek("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	after, _ := cache.GetItemMeta("key")
+	assert.Equal(t, meta.ExpireAt, after.ExpireAt, "Expected Peek not to extend the TTL")
+	assert.Equal(t, int64(0), cache.GetMetrics().Retrievals)
+
+	<-time.After(100 * time.Millisecond)
+	_, exists = cache.Peek("key")
+	assert.False(t, exists)
+}
+
+func TestCache_Pop(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("token", "secret")
+
+	var wins int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data, exists := cache.Pop("token"); exists {
+				assert.Equal(t, "secret", data)
+				atomic.AddInt32(&wins, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&wins), "Expected a single caller to get the value")
+	assert.Equal(t, 0, cache.Count())
+	assert.Equal(t, int64(1), cache.GetMetrics().Hits)
+
+	copies := 0
+	cache.SetValueCopier(func(value interface{}) interface{} {
+		copies++
+		return value
+	}, false)
+	cache.Set("token", "secret")
+	data, _ := cache.Pop("token")
+	assert.Equal(t, "secret", data)
+	assert.Equal(t, 1, copies, "Expected Pop to copy the value like Get")
+}
+
+func TestCache_RemoveIf(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("key", "v1")
+
+	unchanged := func(expected interface{}) func(value interface{}) bool {
+		return func(value interface{}) bool { return value == expected }
+	}
+	assert.False(t, cache.RemoveIf("key", unchanged("v0")))
+	assert.True(t, cache.Has("key"))
+	assert.True(t, cache.RemoveIf("key", unchanged("v1")))
+	assert.False(t, cache.Has("key"))
+	assert.False(t, cache.RemoveIf("missing", func(value interface{}) bool { return true }))
+}
+
+func TestCache_RemoveFunc(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	removed := make(chan string, 10)
+	cache.SetRemovalCallback(func(key string, value interface{}, reason EventType) {
+		if reason == EventRemoved {
+			removed <- key
+		}
+	})
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("key_%d", i), i)
+	}
+
+	count := cache.RemoveFunc(func(key string, value interface{}) bool {
+		return value.(int)%2 == 0
+	})
+	assert.Equal(t, 5, count)
+	assert.Equal(t, 5, cache.Count())
+	cache.Drain()
+	assert.Len(t, removed, 5)
+	assert.Equal(t, 0, cache.CountFunc(func(key string, value interface{}) bool { return value.(int)%2 == 0 }))
+}
+
+func TestCache_Keys(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	assert.Empty(t, cache.Keys())
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.SetWithTTL("expired", 3, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	keys := cache.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestCache_Items(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	assert.Empty(t, cache.Items())
+
+	cache.Set("a", 1)
+	cache.Set("b", []int{2})
+	cache.SetWithTTL("expired", 3, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": []int{2}}, cache.Items())
+
+	cache.SetValueCopier(func(value interface{}) interface{} {
+		if slice, ok := value.([]int); ok {
+			return append([]int(nil), slice...)
+		}
+		return value
+	}, false)
+	items := cache.Items()
+	items["b"].([]int)[0] = 5
+	data, _ := cache.Get("b")
+	assert.Equal(t, []int{2}, data, "Expected copies of the values")
+}
+
+func TestCache_Range(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	for i := 0; i < 10; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+	cache.SetWithTTL("expired", 10, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+
+	sum := 0
+	cache.Range(func(key string, value interface{}) bool {
+		assert.NotEqual(t, "expired", key)
+		sum += value.(int)
+		return true
+	})
+	assert.Equal(t, 45, sum)
+
+	calls := 0
+	cache.Range(func(key string, value interface{}) bool {
+		calls++
+		return calls < 3
+	})
+	assert.Equal(t, 3, calls, "Expected the iteration to stop when f returns false")
+}
+
+func TestCache_Has(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetWithTTL("key", "value", 50*time.Millisecond)
+	<-time.After(30 * time.Millisecond)
+	assert.True(t, cache.Has("key"))
+	assert.False(t, cache.Has("missing"))
+	<-time.After(30 * time.Millisecond)
+	assert.False(t, cache.Has("key"), "Expected Has to not extend the TTL")
+	assert.Equal(t, int64(0), cache.GetMetrics().Retrievals)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { cache.Has("key") }))
+}
+
+func TestCache_CountFunc(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Set("b", "two")
+	cache.Set("c", 3)
+	ints := cache.CountFunc(func(key string, value interface{}) bool {
+		_, isInt := value.(int)
+		return isInt
+	})
+	assert.Equal(t, 2, ints)
+}
+
+func TestCache_SetRemovalCallback(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	reasons := make(chan string, 3)
+	cache.SetRemovalCallback(func(key string, value interface{}, reason EventType) {
+		reasons <- key + " " + reason.String()
+	})
+	cache.SetWithTTL("head", "value", time.Hour)
+	cache.SetWithTTL("short", "value", 20*time.Millisecond)
+	cache.Remove("head")
+	cache.SetCacheSizeLimit(1)
+	cache.SetWithTTL("new", "value", 20*time.Millisecond)
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		select {
+		case reason := <-reasons:
+			got = append(got, reason)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 3 removals, got %v", got)
+		}
+	}
+	assert.ElementsMatch(t, []string{"head remove", "short evict", "new expire"}, got)
+}
+
+func TestCache_RemoveWakesScheduler(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetWithTTL("head", "value", time.Minute)
+	cache.SetWithTTL("tail", "value", time.Hour)
+	// waitForSchedule reports whether the scheduler plans its next sweep more than half an hour ahead, or not
+	waitForSchedule := func(late bool) bool {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			cache.mutex.Lock()
+			expirationTime := cache.expirationTime
+			cache.mutex.Unlock()
+			if expirationTime.After(time.Now().Add(30*time.Minute)) == late {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(t, waitForSchedule(false), "Expected the scheduler to wait for the head")
+	cache.Remove("head")
+	assert.True(t, waitForSchedule(true), "Expected Remove to wake the scheduler for the next item")
+}
+
+func TestCache_SetOverExpired(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	expired := make(chan string, 1)
+	cache.SetExpirationCallback(func(key string, value interface{}) {
+		expired <- key
+	})
+	cache.SetWithTTL("key", "old", time.Hour)
+	cache.mutex.Lock()
+	item, _ := cache.items.Get("key")
+	item.ExpireAt = time.Now().Add(-time.Second)
+	cache.mutex.Unlock()
+
+	cache.Set("key", "new")
+	select {
+	case key := <-expired:
+		assert.Equal(t, "key", key)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the expiration callback for the expired item a write replaced")
+	}
+	assert.Equal(t, int64(1), cache.GetMetrics().Evicted, "Expected the expiration to count like a sweep")
+	data, _ := cache.Get("key")
+	assert.Equal(t, "new", data)
+}
+
+func TestCache_UseAfterClose(t *testing.T) {
+	cache := NewCache()
+	cache.Set("key", "value")
+	cache.Close()
+	cache.Close()
+
+	assert.True(t, cache.IsClosed())
+	done := make(chan struct{})
+	go func() {
+		cache.Set("key", "value")
+		cache.SetTTL(time.Second)
+		cache.Update("key", func(data interface{}, exists bool) (interface{}, time.Duration) { return 1, 0 })
+		assert.False(t, cache.Remove("key"))
+		_, exists := cache.Get("key")
+		assert.False(t, exists)
+		assert.Equal(t, ErrCacheClosed, cache.Txn(func(tx *Tx) error {
+			tx.Set("key", "value")
+			return nil
+		}))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected operations on a closed cache to return")
+	}
+	assert.Equal(t, 0, cache.Count())
+}
+
+func TestCache_EarlierItemReschedulesExpiration(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	expired := make(chan string, 100)
+	cache.SetExpirationCallback(func(key string, value interface{}) {
+		expired <- key
+	})
+	cache.SetWithTTL("late", "value", time.Hour)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cache.SetWithTTL(strconv.Itoa(i), "value", time.Duration(10+i)*time.Millisecond)
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 20; i++ {
+		select {
+		case <-expired:
+		case <-time.After(time.Second):
+			t.Fatalf("Expected item %d to expire", i)
+		}
+	}
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "Expected the items to expire on time")
+}
+
+func TestCache_GetItemMeta(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	before := time.Now()
+	cache.SetWithTTL("key", "value", time.Minute)
+	cache.Set("forever", "value")
+	cache.Get("key")
+	cache.Get("key")
+
+	meta, exists := cache.GetItemMeta("key")
+	assert.True(t, exists)
+	assert.Equal(t, "key", meta.Key)
+	assert.Equal(t, "value", meta.Value)
+	assert.Equal(t, time.Minute, meta.TTL)
+	assert.Equal(t, int64(2), meta.Hits)
+	assert.False(t, meta.CreatedAt.Before(before))
+	assert.True(t, meta.ExpireAt.After(meta.CreatedAt.Add(59*time.Second)))
+
+	meta, _ = cache.GetItemMeta("forever")
+	assert.Equal(t, ItemNotExpire, meta.TTL)
+	assert.True(t, meta.ExpireAt.IsZero())
+	_, exists = cache.GetItemMeta("missing")
+	assert.False(t, exists)
+	assert.Equal(t, int64(2), cache.GetMetrics().Retrievals)
+}
+
+func TestCache_CheckExpirationCallbackUsesCache(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	checked := make(chan struct{}, 10)
+	cache.SetCheckExpirationCallback(func(key string, value interface{}) bool {
+		_, pinned := cache.Get("pinned:" + key)
+		checked <- struct{}{}
+		return !pinned
+	})
+	cache.Set("pinned:keep", true)
+	cache.SetWithTTL("keep", "value", 20*time.Millisecond)
+	cache.SetWithTTL("drop", "value", 20*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-checked:
+		case <-time.After(time.Second):
+			t.Fatal("Expected the callback to run without deadlocking")
+		}
+	}
+	<-time.After(5 * time.Millisecond)
+	assert.True(t, cache.Has("keep"))
+	assert.False(t, cache.Has("drop"))
+}
+
+func TestCache_CloseWaitsForCallbacks(t *testing.T) {
+	cache := NewCache()
+
+	var finished int32
+	cache.SetExpirationCallback(func(key string, value interface{}) {
+		<-time.After(50 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	})
+	cache.SetWithTTL("key", "value", time.Millisecond)
+	for cache.Has("key") || cache.GetMetrics().Evicted == 0 {
+		<-time.After(time.Millisecond)
+	}
+	cache.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished), "Expected Close to wait for the callback")
+}
+
+func TestCache_SetIfVersion(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	assert.True(t, cache.SetIfVersion("key", 1, 0), "Expected version 0 to create a missing key")
+	assert.False(t, cache.SetIfVersion("key", 2, 0))
+	data, version, exists := cache.GetWithVersion("key")
+	assert.True(t, exists)
+	assert.Equal(t, 1, data)
+
+	cache.Set("key", 3)
+	assert.False(t, cache.SetIfVersion("key", 4, version), "Expected a stale version to be rejected")
+	_, newVersion, _ := cache.GetWithVersion("key")
+	assert.True(t, newVersion > version)
+	assert.True(t, cache.SetIfVersion("key", 4, newVersion))
+
+	_, version, _ = cache.GetWithVersion("key")
+	cache.Remove("key")
+	cache.Set("key", 5)
+	_, newVersion, _ = cache.GetWithVersion("key")
+	assert.True(t, newVersion > version, "Expected a new key to not reuse a version")
+	meta, _ := cache.GetItemMeta("key")
+	assert.Equal(t, newVersion, meta.Version)
+}
+
+func TestCache_CompareAndSwap(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	assert.False(t, cache.CompareAndSwap("key", nil, 1), "Expected a missing key not to match")
+	cache.Set("key", []string{"a"})
+	assert.False(t, cache.CompareAndSwap("key", []string{"b"}, []string{"c"}))
+	assert.True(t, cache.CompareAndSwap("key", []string{"a"}, []string{"a", "b"}))
+	data, _ := cache.Get("key")
+	assert.Equal(t, []string{"a", "b"}, data)
+
+	cache.SetEqualFunc(func(a, b interface{}) bool {
+		return len(a.([]string)) == len(b.([]string))
+	})
+	assert.True(t, cache.CompareAndSwap("key", []string{"x", "y"}, []string{"c"}), "Expected the equal function to be used")
+	data, _ = cache.Get("key")
+	assert.Equal(t, []string{"c"}, data)
+}
+
+func TestCache_Replace(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	assert.False(t, cache.Replace("key", 1, time.Minute), "Expected a missing key not to be stored")
+	assert.False(t, cache.Has("key"))
+	cache.SetWithTTL("key", 1, time.Minute)
+	assert.True(t, cache.Replace("key", 2, time.Hour))
+	data, _ := cache.Get("key")
+	assert.Equal(t, 2, data)
+	ttl, _ := cache.GetTTL("key")
+	assert.Equal(t, time.Hour, ttl)
+
+	cache.SetWithTTL("expired", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	assert.False(t, cache.Replace("expired", 2, time.Minute), "Expected an expired key not to be resurrected")
+}
+
+func TestCache_Swap(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	added := 0
+	cache.SetNewItemCallback(func(key string, value interface{}) {
+		added++
+	})
+
+	previous, existed := cache.Swap("key", 1, time.Minute)
+	assert.False(t, existed)
+	assert.Nil(t, previous)
+	previous, existed = cache.Swap("key", 2, time.Minute)
+	assert.True(t, existed)
+	assert.Equal(t, 1, previous)
+	data, _ := cache.Get("key")
+	assert.Equal(t, 2, data)
+	assert.Equal(t, 1, added, "Expected only the first swap to add an item")
+}
+
+func TestCache_ConditionalWritesCopyOnSet(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetValueCopier(func(value interface{}) interface{} {
+		return append([]int(nil), value.([]int)...)
+	}, true)
+
+	value := []int{1}
+	previous, _ := cache.Swap("key", value, time.Minute)
+	assert.Nil(t, previous)
+	value[0] = 42
+	previous, existed := cache.Swap("key", []int{2}, time.Minute)
+	assert.True(t, existed)
+	assert.Equal(t, []int{1}, previous, "Expected Swap to copy the stored value")
+	previous.([]int)[0] = 42
+
+	value = []int{3}
+	assert.True(t, cache.CompareAndSwap("key", []int{2}, value))
+	value[0] = 42
+	value = []int{4}
+	assert.True(t, cache.Replace("key", value, time.Minute))
+	value[0] = 42
+	data, _ := cache.Get("key")
+	assert.Equal(t, []int{4}, data, "Expected Replace to copy the value")
+
+	_, version, _ := cache.GetWithVersion("key")
+	value = []int{5}
+	assert.True(t, cache.SetIfVersion("key", value, version))
+	value[0] = 42
+	data, _ = cache.Get("key")
+	assert.Equal(t, []int{5}, data, "Expected SetIfVersion to copy the value")
+}
+
+func TestCache_GetIfChanged(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	_, version, changed := cache.GetIfChanged("key", 0)
+	assert.Equal(t, uint64(0), version)
+	assert.False(t, changed, "Expected a missing key to be unchanged for version 0")
+
+	cache.Set("key", "blob")
+	data, version, changed := cache.GetIfChanged("key", 0)
+	assert.True(t, changed)
+	assert.Equal(t, "blob", data)
+	data, _, changed = cache.GetIfChanged("key", version)
+	assert.False(t, changed)
+	assert.Nil(t, data)
+
+	cache.Remove("key")
+	_, newVersion, changed := cache.GetIfChanged("key", version)
+	assert.True(t, changed, "Expected a removal to be a change")
+	assert.Equal(t, uint64(0), newVersion)
+}
+
+func TestCache_SetWithValidator(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetWithValidator("page", "<html>", time.Minute, `"v1"`)
+	validator, exists := cache.GetValidator("page")
+	assert.True(t, exists)
+	assert.Equal(t, `"v1"`, validator)
+	meta, _ := cache.GetItemMeta("page")
+	assert.Equal(t, `"v1"`, meta.Validator)
+	assert.Equal(t, int64(0), cache.GetMetrics().Retrievals)
+
+	cache.Set("page", "<html>changed")
+	validator, _ = cache.GetValidator("page")
+	assert.Equal(t, "", validator, "Expected a plain write to clear the validator")
+	_, exists = cache.GetValidator("missing")
+	assert.False(t, exists)
+}
+
+func TestCache_SetWithCheckExpiration(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	checks := make(chan string, 10)
+	cache.SetWithCheckExpiration("keep", "value", 10*time.Millisecond, func(key string, value interface{}) bool {
+		checks <- key
+		return false
+	})
+	cache.SetWithTTL("drop", "value", 10*time.Millisecond)
+
+	select {
+	case key := <-checks:
+		assert.Equal(t, "keep", key)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the check of the item to run")
+	}
+	<-time.After(5 * time.Millisecond)
+	assert.True(t, cache.Has("keep"))
+	assert.False(t, cache.Has("drop"), "Expected items without a check to expire")
+
+	cache.SetWithTTL("keep", "value", 10*time.Millisecond)
+	<-time.After(50 * time.Millisecond)
+	assert.False(t, cache.Has("keep"), "Expected a plain write to clear the check")
+}
+
+func TestCache_SetMaxTTLExtension(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetMaxTTLExtension(40 * time.Millisecond)
+
+	cache.SetWithTTL("lease", "value", 40*time.Millisecond)
+	start := time.Now()
+	for time.Since(start) < 70*time.Millisecond {
+		_, exists := cache.Get("lease")
+		assert.True(t, exists, "Expected hits to extend the lease")
+		<-time.After(10 * time.Millisecond)
+	}
+	<-time.After(30 * time.Millisecond)
+	_, exists := cache.Get("lease")
+	assert.False(t, exists, "Expected the lease to end at most the extension after its first deadline")
+}
+
+func TestCache_ProcessExpirations(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var expired []string
+	cache.SetExpirationCallback(func(key string, value interface{}) {
+		expired = append(expired, key)
+	})
+	cache.SetWithTTL("hour", 1, time.Hour)
+	cache.SetWithTTL("day", 2, 24*time.Hour)
+	cache.SetWithTTL("forever", 3, ItemNotExpire)
+
+	cache.ProcessExpirations(time.Now().Add(30 * time.Minute))
+	cache.Drain()
+	assert.Equal(t, 3, cache.Count())
+
+	cache.ProcessExpirations(time.Now().Add(2 * time.Hour))
+	cache.Drain()
+	assert.Equal(t, []string{"hour"}, expired)
+	assert.Equal(t, 2, cache.Count())
+	assert.True(t, cache.Has("day"))
+}
+
+func TestNewMapCache(t *testing.T) {
+	cache := NewMapCache()
+	defer cache.Close()
+
+	removed := make(chan string, 10)
+	cache.SetRemovalCallback(func(key string, value interface{}, reason EventType) {
+		removed <- key
+	})
+	cache.SetTTL(time.Millisecond)
+	cache.SetWithTTL("key", "value", time.Millisecond)
+	cache.Set("other", "value")
+	time.Sleep(10 * time.Millisecond)
+
+	data, exists := cache.Get("key")
+	assert.True(t, exists, "Expected TTLs to be ignored")
+	assert.Equal(t, "value", data)
+	assert.Equal(t, int64(0), cache.GetMetrics().QueuePushes, "Expected no expiration queue")
+	assert.Nil(t, cache.Healthy())
+	assert.Nil(t, cache.VerifyIntegrity())
+
+	cache.SetCacheSizeLimit(1)
+	cache.Set("third", "value")
+	assert.Equal(t, 1, cache.Count())
+	assert.NotEqual(t, "", <-removed, "Expected the size limit to evict an item")
+	cache.Purge()
+	assert.Equal(t, 0, cache.Count())
+
+	config, err := NewCacheFromConfig(Config{DisableExpiration: true})
+	assert.Nil(t, err)
+	config.Close()
+	_, err = NewCacheFromConfig(Config{DisableExpiration: true, TTL: time.Second})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,93 @@
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+// Child is a ChildOf a Cache
+type Child = ChildOf[string]
+
+// ChildOf is an overlay on a parent cache, for instance to memoize within a single request on top of a shared cache.
+// Lookups fall through to the parent, writes and removals stay in the child until Promote applies them to the parent.
+// A child has no expiration goroutine, its own items live until it is closed.
+type ChildOf[K comparable] struct {
+	parent *CacheOf[K]
+	mutex  sync.Mutex
+	writes map[K]childWrite
+	isDone bool
+}
+
+type childWrite struct {
+	data    interface{}
+	ttl     time.Duration
+	removed bool
+}
+
+// NewChild creates an overlay on the cache, close it when done
+func (cache *CacheOf[K]) NewChild() *ChildOf[K] {
+	return &ChildOf[K]{parent: cache, writes: make(map[K]childWrite)}
+}
+
+// Set stores the item in the child with the global TTL of the parent
+func (child *ChildOf[K]) Set(key K, data interface{}) {
+	child.SetWithTTL(key, data, ItemExpireWithGlobalTTL)
+}
+
+// SetWithTTL stores the item in the child, the TTL is used when the item is promoted to the parent
+func (child *ChildOf[K]) SetWithTTL(key K, data interface{}, ttl time.Duration) {
+	child.mutex.Lock()
+	defer child.mutex.Unlock()
+	if !child.isDone {
+		child.writes[key] = childWrite{data: data, ttl: ttl}
+	}
+}
+
+// Get returns the item of the child, or looks it up in the parent when the child did not write or remove the key
+func (child *ChildOf[K]) Get(key K) (interface{}, bool) {
+	child.mutex.Lock()
+	write, exists := child.writes[key]
+	child.mutex.Unlock()
+	if exists {
+		return write.data, !write.removed
+	}
+	return child.parent.Get(key)
+}
+
+// Remove hides the key from the lookups of the child, and reports whether it existed in the child or the parent
+func (child *ChildOf[K]) Remove(key K) bool {
+	child.mutex.Lock()
+	write, exists := child.writes[key]
+	if !child.isDone {
+		child.writes[key] = childWrite{removed: true}
+	}
+	child.mutex.Unlock()
+	if exists {
+		return !write.removed
+	}
+	_, _, exists = child.parent.peek(key)
+	return exists
+}
+
+// Promote applies the writes and removals of the child to the parent and forgets them
+func (child *ChildOf[K]) Promote() {
+	child.mutex.Lock()
+	writes := child.writes
+	child.writes = make(map[K]childWrite)
+	child.mutex.Unlock()
+	for key, write := range writes {
+		if write.removed {
+			child.parent.Remove(key)
+		} else {
+			child.parent.SetWithTTL(key, write.data, write.ttl)
+		}
+	}
+}
+
+// Close discards the writes that were not promoted, later writes are ignored
+func (child *ChildOf[K]) Close() {
+	child.mutex.Lock()
+	child.writes = make(map[K]childWrite)
+	child.isDone = true
+	child.mutex.Unlock()
+}
@@ -0,0 +1,41 @@
+package ttlcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_NewChild(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("shared", "parent")
+	cache.Set("removed", "parent")
+
+	child := cache.NewChild()
+	child.Set("local", "child")
+	child.Set("shared", "child")
+	assert.True(t, child.Remove("removed"))
+
+	data, _ := child.Get("shared")
+	assert.Equal(t, "child", data)
+	_, exists := child.Get("removed")
+	assert.False(t, exists)
+	data, _ = cache.Get("shared")
+	assert.Equal(t, "parent", data, "Expected the parent to be untouched")
+	_, exists = cache.Get("local")
+	assert.False(t, exists)
+
+	child.Promote()
+	data, _ = cache.Get("shared")
+	assert.Equal(t, "child", data)
+	_, exists = cache.Get("removed")
+	assert.False(t, exists)
+
+	discarded := cache.NewChild()
+	discarded.Set("discarded", true)
+	discarded.Close()
+	discarded.Set("late", true)
+	discarded.Promote()
+	assert.Equal(t, 2, cache.Count())
+}
@@ -0,0 +1,134 @@
+package ttlcache
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Node is a cache a Client spreads keys over. *Cache implements it, so does any wrapper around a remote cache server.
+type Node interface {
+	Get(key string) (interface{}, bool)
+	SetWithTTL(key string, data interface{}, ttl time.Duration)
+	Remove(key string) bool
+}
+
+type ringPoint struct {
+	hash uint32
+	name string
+}
+
+// ringHash places a key or a virtual node on the ring. CRC32 alone puts similar strings, such as the virtual nodes
+// of one name, close together, the finalizer of MurmurHash3 spreads them over the ring.
+func ringHash(s string) uint32 {
+	hash := crc32.ChecksumIEEE([]byte(s))
+	hash ^= hash >> 16
+	hash *= 0x85ebca6b
+	hash ^= hash >> 13
+	hash *= 0xc2b2ae35
+	hash ^= hash >> 16
+	return hash
+}
+
+// Client spreads keys over several nodes with consistent hashing. Every node is placed on a hash ring many times,
+// as virtual nodes, so keys are evenly distributed and adding or removing a node only moves the keys of that node.
+type Client struct {
+	mutex        sync.RWMutex
+	virtualNodes int
+	nodes        map[string]Node
+	ring         []ringPoint
+}
+
+// NewClient creates a Client which places every node virtualNodes times on the ring, 100 is a sensible choice
+func NewClient(virtualNodes int) *Client {
+	if virtualNodes < 1 {
+		virtualNodes = 1
+	}
+	return &Client{virtualNodes: virtualNodes, nodes: make(map[string]Node)}
+}
+
+// AddNode adds a node under a unique name, the name determines its place on the ring.
+// Adding a name again replaces the node.
+func (client *Client) AddNode(name string, node Node) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if _, exists := client.nodes[name]; !exists {
+		for i := 0; i < client.virtualNodes; i++ {
+			// the separator keeps the points of names such as "x" and "0x" apart
+			point := name + "#" + strconv.Itoa(i)
+			client.ring = append(client.ring, ringPoint{hash: ringHash(point), name: name})
+		}
+		sort.Slice(client.ring, func(i, j int) bool {
+			if client.ring[i].hash != client.ring[j].hash {
+				return client.ring[i].hash < client.ring[j].hash
+			}
+			return client.ring[i].name < client.ring[j].name
+		})
+	}
+	client.nodes[name] = node
+}
+
+// RemoveNode removes a node, its keys are spread over the remaining nodes
+func (client *Client) RemoveNode(name string) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	if _, exists := client.nodes[name]; !exists {
+		return
+	}
+	delete(client.nodes, name)
+	ring := client.ring[:0]
+	for _, point := range client.ring {
+		if point.name != name {
+			ring = append(ring, point)
+		}
+	}
+	client.ring = ring
+}
+
+// NodeFor returns the name and the node responsible for the key, it returns false when there are no nodes
+func (client *Client) NodeFor(key string) (string, Node, bool) {
+	client.mutex.RLock()
+	defer client.mutex.RUnlock()
+	if len(client.ring) == 0 {
+		return "", nil, false
+	}
+	hash := ringHash(key)
+	i := sort.Search(len(client.ring), func(i int) bool { return client.ring[i].hash >= hash })
+	if i == len(client.ring) {
+		i = 0
+	}
+	name := client.ring[i].name
+	return name, client.nodes[name], true
+}
+
+// Get looks up the key on its node
+func (client *Client) Get(key string) (interface{}, bool) {
+	_, node, ok := client.NodeFor(key)
+	if !ok {
+		return nil, false
+	}
+	return node.Get(key)
+}
+
+// Set stores the key on its node with the global TTL of that node
+func (client *Client) Set(key string, data interface{}) {
+	client.SetWithTTL(key, data, ItemExpireWithGlobalTTL)
+}
+
+// SetWithTTL stores the key on its node, without nodes it does nothing
+func (client *Client) SetWithTTL(key string, data interface{}, ttl time.Duration) {
+	if _, node, ok := client.NodeFor(key); ok {
+		node.SetWithTTL(key, data, ttl)
+	}
+}
+
+// Remove removes the key from its node
+func (client *Client) Remove(key string) bool {
+	_, node, ok := client.NodeFor(key)
+	if !ok {
+		return false
+	}
+	return node.Remove(key)
+}
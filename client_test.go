@@ -0,0 +1,73 @@
+package ttlcache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SpreadsKeys(t *testing.T) {
+	client := NewClient(100)
+	_, exists := client.Get("key")
+	assert.False(t, exists, "Expected a client without nodes to find nothing")
+
+	caches := make(map[string]*Cache)
+	for _, name := range []string{"a", "b", "c"} {
+		caches[name] = NewCache()
+		defer caches[name].Close()
+		client.AddNode(name, caches[name])
+	}
+
+	for i := 0; i < 3000; i++ {
+		client.Set(fmt.Sprintf("key_%d", i), i)
+	}
+	for name, cache := range caches {
+		assert.InDelta(t, 1000, cache.Count(), 250, "Expected node %s to hold about a third of the keys", name)
+	}
+	data, exists := client.Get("key_42")
+	assert.True(t, exists)
+	assert.Equal(t, 42, data)
+	assert.True(t, client.Remove("key_42"))
+	assert.False(t, client.Remove("key_42"))
+}
+
+func TestClient_MinimalKeyMovement(t *testing.T) {
+	client := NewClient(100)
+	for _, name := range []string{"a", "b", "c"} {
+		client.AddNode(name, nil)
+	}
+	owners := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("key_%d", i)
+		owners[key], _, _ = client.NodeFor(key)
+	}
+
+	client.AddNode("d", nil)
+	moved := 0
+	for key, owner := range owners {
+		name, _, _ := client.NodeFor(key)
+		if name != owner {
+			assert.Equal(t, "d", name, "Expected keys to only move to the new node")
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 250, "Expected about a quarter of the keys to move")
+
+	client.RemoveNode("d")
+	for key, owner := range owners {
+		name, _, _ := client.NodeFor(key)
+		assert.Equal(t, owner, name, "Expected keys to return to their node")
+	}
+}
+
+func TestClient_RingPointsOfSimilarNames(t *testing.T) {
+	client := NewClient(100)
+	client.AddNode("0x", nil)
+	client.AddNode("x", nil)
+	hashes := make(map[uint32]bool)
+	for _, point := range client.ring {
+		hashes[point.hash] = true
+	}
+	assert.Equal(t, 200, len(hashes), "Expected the virtual nodes of different names not to share points")
+}
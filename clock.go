@@ -0,0 +1,56 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// Clock is the source of time of a cache, see SetClock. The ttlcachetest package has a fake clock for tests.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// NewTimer returns a timer which sends the time on its channel once the duration has passed
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer of a Clock, it works like time.Timer
+type Timer interface {
+	// C returns the channel the time is sent on
+	C() <-chan time.Time
+	// Reset changes the timer to expire after the duration, it must be stopped or expired and drained
+	Reset(d time.Duration) bool
+	// Stop prevents the timer from firing
+	Stop() bool
+}
+
+// realClock is the Clock of the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (timer realTimer) C() <-chan time.Time {
+	return timer.Timer.C
+}
+
+// SetClock replaces the clock the cache reads the time from and waits with for expirations, for tests that must not
+// depend on real time. It should be called before items are stored, existing items move to the new clock too.
+func (cache *CacheOf[K]) SetClock(clock Clock) {
+	cache.mutex.Lock()
+	cache.clock = clock
+	cache.items.Range(func(_ K, item *ItemOf[K]) bool {
+		item.clock = clock
+		return true
+	})
+	cache.invalidateReads()
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+}
@@ -0,0 +1,106 @@
+// Command ttlcachectl inspects a live cache through the admin socket opened with Cache.ServeAdmin.
+//
+// Usage:
+//
+//	ttlcachectl -socket /run/app/cache.sock keys [substring]
+//	ttlcachectl -socket /run/app/cache.sock get <key>
+//	ttlcachectl -socket /run/app/cache.sock del <key>
+//	ttlcachectl -socket /run/app/cache.sock stats
+//	ttlcachectl -socket /run/app/cache.sock purge
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jadevelopmentgrp/TTLCache"
+)
+
+func main() {
+	socket := flag.String("socket", "", "path of the unix socket the cache is served on")
+	timeout := flag.Duration("timeout", 5*time.Second, "time to wait for an answer")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s -socket <path> keys [substring] | get <key> | del <key> | stats | purge\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *socket == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	response, err := send(*socket, *timeout, strings.Join(flag.Args(), " "))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(report(os.Stdout, strings.ToLower(flag.Arg(0)), response))
+}
+
+func send(socket string, timeout time.Duration, command string) (ttlcache.AdminResponse, error) {
+	var response ttlcache.AdminResponse
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return response, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return response, err
+	}
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return response, err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return response, err
+	}
+	return response, json.Unmarshal(line, &response)
+}
+
+// report writes the response for a human and returns the exit code
+func report(w io.Writer, command string, response ttlcache.AdminResponse) int {
+	if response.Error != "" {
+		fmt.Fprintln(os.Stderr, response.Error)
+		return 1
+	}
+	switch command {
+	case "keys":
+		for _, key := range response.Keys {
+			fmt.Fprintln(w, key)
+		}
+	case "get":
+		if !response.Found {
+			fmt.Fprintln(os.Stderr, "not found")
+			return 1
+		}
+		fmt.Fprintln(w, response.Value)
+		if response.Remaining == "never" {
+			fmt.Fprintln(w, "(does not expire)")
+		} else {
+			fmt.Fprintf(w, "(expires in %s)\n", response.Remaining)
+		}
+	case "del":
+		if !response.Found {
+			fmt.Fprintln(os.Stderr, "not found")
+			return 1
+		}
+	case "stats":
+		names := make([]string, 0, len(response.Stats))
+		for name := range response.Stats {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s\t%d\n", name, response.Stats[name])
+		}
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package ttlcache
+
+// compactMinItems is the peak number of items below which automatic compaction is not worth it
+const compactMinItems = 1024
+
+// Compact rebuilds the map and the expiration queue with room for the current items only. Go maps never shrink,
+// so after a mass removal they keep the memory of their largest size. Sweeps and bulk removals compact automatically
+// once the cache holds less than a quarter of its peak number of items, Compact does so right away.
+func (cache *CacheOf[K]) Compact() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.compact()
+}
+
+// compactIfShrunk compacts when the cache shrank to a quarter of its peak, the cache mutex must be held
+func (cache *CacheOf[K]) compactIfShrunk() {
+	if cache.peakItems >= compactMinItems && cache.items.Len() < cache.peakItems/4 {
+		cache.compact()
+	}
+}
+
+// compact copies the items to a new map and queue slice, the cache mutex must be held. Stores set with SetStore
+// manage their own memory and are left as they are.
+func (cache *CacheOf[K]) compact() {
+	if current, isMap := cache.items.(mapStore[K]); isMap {
+		items := newMapStore[K](len(current))
+		for key, item := range current {
+			items[key] = item
+		}
+		cache.items = items
+	}
+	cache.compactScan()
+	if cache.priorityQueue != nil {
+		cache.priorityQueue.items = append([]*ItemOf[K](nil), cache.priorityQueue.items...)
+	}
+	cache.peakItems = cache.items.Len()
+}
@@ -0,0 +1,53 @@
+package ttlcache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Compact(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	for i := 0; i < 2000; i++ {
+		cache.SetWithTTL(Key("old", i), i, time.Minute)
+	}
+	cache.Set("kept", "value")
+	cache.Compact()
+	cache.mutex.Lock()
+	assert.Equal(t, 2001, cache.peakItems)
+	cache.mutex.Unlock()
+
+	cache.ProcessExpirations(time.Now().Add(time.Hour))
+	cache.mutex.Lock()
+	assert.Equal(t, 1, cache.peakItems, "Expected the sweep to compact")
+	assert.Equal(t, 1, cap(cache.priorityQueue.items))
+	cache.mutex.Unlock()
+	data, exists := cache.Get("kept")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	assert.Nil(t, cache.VerifyIntegrity())
+}
+
+func TestCache_PurgeReleasesMap(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	for i := 0; i < 2000; i++ {
+		cache.SetWithTTL(Key("old", i), i, time.Minute)
+	}
+	cache.mutex.Lock()
+	before := reflect.ValueOf(cache.items).Pointer()
+	cache.mutex.Unlock()
+	cache.Purge()
+	cache.mutex.Lock()
+	assert.NotEqual(t, before, reflect.ValueOf(cache.items).Pointer(), "Expected Purge to replace the grown map")
+	cache.mutex.Unlock()
+
+	cache.Set("new", "value")
+	assert.Equal(t, 1, cache.Count())
+	assert.Nil(t, cache.VerifyIntegrity())
+}
@@ -0,0 +1,148 @@
+package ttlcache
+
+import (
+	"strings"
+	"time"
+)
+
+// Config holds the settings of a cache, so it can be read from a configuration file.
+// Durations are nanoseconds in JSON, YAML decoders usually accept values like "5m" too.
+// The callbacks can only be set from code.
+type Config struct {
+	// TTL is the global TTL, zero means items do not expire unless they have an individual TTL
+	TTL time.Duration `json:"ttl" yaml:"ttl"`
+	// SkipTTLExtensionOnHit keeps Get from extending the life of items, see Cache.SkipTtlExtensionOnHit
+	SkipTTLExtensionOnHit bool `json:"skipTtlExtensionOnHit" yaml:"skipTtlExtensionOnHit"`
+	// SizeLimit is the maximum number of items, zero means no limit
+	SizeLimit int `json:"sizeLimit" yaml:"sizeLimit"`
+	// TTLJitter is the fraction between 0 and 1 by which TTLs are randomly shortened
+	TTLJitter float64 `json:"ttlJitter" yaml:"ttlJitter"`
+	// MaxCost is the maximum total cost of the items as returned by Weigher, zero means no limit
+	MaxCost int64                                     `json:"maxCost" yaml:"maxCost"`
+	Weigher func(key string, value interface{}) int64 `json:"-" yaml:"-"`
+	// DisableExpiration creates a cache without any expiration machinery, see NewMapCache
+	DisableExpiration bool `json:"disableExpiration" yaml:"disableExpiration"`
+	// Shards is the number of shards of NewShardedCacheFromConfig, which requires it to be set. SizeLimit and MaxCost
+	// apply to each shard.
+	Shards int `json:"shards" yaml:"shards"`
+
+	ExpirationCallback      func(key string, value interface{})      `json:"-" yaml:"-"`
+	CheckExpirationCallback func(key string, value interface{}) bool `json:"-" yaml:"-"`
+	NewItemCallback         func(key string, value interface{})      `json:"-" yaml:"-"`
+	// CaseInsensitiveKeys normalizes keys with FoldKeyCase, KeyNormalizer sets another normalizer
+	CaseInsensitiveKeys bool                    `json:"caseInsensitiveKeys" yaml:"caseInsensitiveKeys"`
+	KeyNormalizer       func(key string) string `json:"-" yaml:"-"`
+	// ValueCopier makes Get return copies of values, and Set store copies with CopyOnSet, see Cache.SetValueCopier
+	ValueCopier func(value interface{}) interface{} `json:"-" yaml:"-"`
+	CopyOnSet   bool                                `json:"copyOnSet" yaml:"copyOnSet"`
+}
+
+// ConfigError lists everything that is wrong with a Config
+type ConfigError struct {
+	Problems []string
+}
+
+func (err *ConfigError) Error() string {
+	return "ttlcache: invalid config: " + strings.Join(err.Problems, "; ")
+}
+
+// Validate returns a *ConfigError when settings are out of range or contradict each other
+func (config Config) Validate() error {
+	var problems []string
+	if config.TTL < 0 {
+		problems = append(problems, "ttl must not be negative, use zero for items without expiration")
+	}
+	if config.SizeLimit < 0 {
+		problems = append(problems, "sizeLimit must not be negative, use zero for no limit")
+	}
+	if config.TTLJitter < 0 || config.TTLJitter > 1 {
+		problems = append(problems, "ttlJitter must be between 0 and 1")
+	}
+	if config.MaxCost < 0 {
+		problems = append(problems, "maxCost must not be negative, use zero for no limit")
+	}
+	if config.MaxCost > 0 && config.Weigher == nil {
+		problems = append(problems, "maxCost needs a Weigher")
+	}
+	if config.DisableExpiration && (config.TTL > 0 || config.CheckExpirationCallback != nil) {
+		problems = append(problems, "disableExpiration conflicts with a ttl or a CheckExpirationCallback")
+	}
+	if config.Shards < 0 {
+		problems = append(problems, "shards must not be negative")
+	}
+	if config.CaseInsensitiveKeys && config.KeyNormalizer != nil {
+		problems = append(problems, "caseInsensitiveKeys conflicts with a KeyNormalizer")
+	}
+	if config.CopyOnSet && config.ValueCopier == nil {
+		problems = append(problems, "copyOnSet needs a ValueCopier")
+	}
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// NewCacheFromConfig validates the config and creates a cache with those settings
+func NewCacheFromConfig(config Config) (*Cache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var cache *Cache
+	if config.DisableExpiration {
+		cache = NewMapCache()
+	} else {
+		cache = NewCache()
+	}
+	config.apply(&cache.CacheOf)
+	return cache, nil
+}
+
+// NewShardedCacheFromConfig validates the config and creates a sharded cache, every shard gets those settings
+func NewShardedCacheFromConfig(config Config) (*ShardedCache, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var problems []string
+	if config.DisableExpiration {
+		problems = append(problems, "disableExpiration is not supported by sharded caches")
+	}
+	if config.Shards == 0 {
+		problems = append(problems, "shards must be set for a sharded cache")
+	}
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	sharded := NewShardedCache(config.Shards)
+	for _, shard := range sharded.Shards() {
+		config.apply(shard)
+	}
+	return sharded, nil
+}
+
+// apply configures a cache with the settings of a valid config
+func (config Config) apply(cache *CacheOf[string]) {
+	cache.SetTTL(config.TTL)
+	cache.SkipTtlExtensionOnHit(config.SkipTTLExtensionOnHit)
+	cache.SetCacheSizeLimit(config.SizeLimit)
+	cache.SetTTLJitter(config.TTLJitter)
+	if config.Weigher != nil {
+		cache.SetWeigher(config.Weigher, config.MaxCost)
+	}
+	if config.ExpirationCallback != nil {
+		cache.SetExpirationCallback(config.ExpirationCallback)
+	}
+	if config.CheckExpirationCallback != nil {
+		cache.SetCheckExpirationCallback(config.CheckExpirationCallback)
+	}
+	if config.NewItemCallback != nil {
+		cache.SetNewItemCallback(config.NewItemCallback)
+	}
+	if config.CaseInsensitiveKeys {
+		cache.SetKeyNormalizer(FoldKeyCase)
+	} else if config.KeyNormalizer != nil {
+		cache.SetKeyNormalizer(config.KeyNormalizer)
+	}
+	if config.ValueCopier != nil {
+		cache.SetValueCopier(config.ValueCopier, config.CopyOnSet)
+	}
+}
@@ -0,0 +1,156 @@
+package ttlcache
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCacheFromConfig(t *testing.T) {
+	var config Config
+	assert.Nil(t, json.Unmarshal([]byte(`{"ttl": 60000000000, "sizeLimit": 2, "ttlJitter": 0.1}`), &config))
+	expired := make(chan string, 1)
+	config.ExpirationCallback = func(key string, value interface{}) { expired <- key }
+
+	cache, err := NewCacheFromConfig(config)
+	assert.Nil(t, err)
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	ttl, _ := cache.GetTTL("a")
+	assert.Equal(t, time.Minute, ttl)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	assert.Equal(t, 2, cache.Count(), "Expected the size limit to apply")
+	assert.NotEqual(t, "", <-expired, "Expected the expiration callback to be set")
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.Nil(t, Config{}.Validate())
+
+	_, err := NewCacheFromConfig(Config{TTL: -time.Second, SizeLimit: -1, TTLJitter: 1.5})
+	configErr, ok := err.(*ConfigError)
+	assert.True(t, ok)
+	assert.Len(t, configErr.Problems, 3)
+	assert.Contains(t, err.Error(), "ttlJitter must be between 0 and 1")
+
+	assert.Nil(t, Config{TTLJitter: 0.5}.Validate(), "Expected jitter to be allowed for individual TTLs")
+	err = Config{CaseInsensitiveKeys: true, KeyNormalizer: strings.TrimSpace}.Validate()
+	assert.Contains(t, err.Error(), "caseInsensitiveKeys conflicts")
+	err = Config{CopyOnSet: true}.Validate()
+	assert.Contains(t, err.Error(), "copyOnSet needs a ValueCopier")
+	err = Config{Shards: -1}.Validate()
+	assert.Contains(t, err.Error(), "shards must not be negative")
+}
+
+func TestNewShardedCacheFromConfig(t *testing.T) {
+	cache, err := NewShardedCacheFromConfig(Config{TTL: time.Minute, SizeLimit: 10, Shards: 4})
+	assert.NoError(t, err)
+	defer cache.Close()
+	assert.Len(t, cache.Shards(), 4)
+	cache.Set("key", "value")
+	ttl, exists := cache.GetTTL("key")
+	assert.True(t, exists)
+	assert.Equal(t, time.Minute, ttl)
+
+	_, err = NewShardedCacheFromConfig(Config{DisableExpiration: true})
+	assert.Error(t, err)
+	_, err = NewShardedCacheFromConfig(Config{Shards: -1})
+	assert.Error(t, err)
+	_, err = NewShardedCacheFromConfig(Config{})
+	assert.Contains(t, err.Error(), "shards must be set")
+}
+
+func TestCache_SetCacheSizeLimit(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	subscription := cache.Subscribe(10)
+	cache.SetWithTTL("soon", 1, time.Second)
+	cache.SetWithTTL("later", 2, time.Minute)
+	cache.SetWithTTL("latest", 3, time.Hour)
+	cache.SetCacheSizeLimit(2)
+	assert.Equal(t, 2, cache.Count())
+	_, exists := cache.Get("soon")
+	assert.False(t, exists, "Expected the item closest to expiration to be evicted")
+
+	cache.Set("new", 4)
+	assert.Equal(t, 2, cache.Count())
+	_, exists = cache.Get("new")
+	assert.True(t, exists, "Expected a new item to always fit")
+	assert.Equal(t, int64(2), cache.GetMetrics().Evicted)
+
+	for event := range subscription.C {
+		if event.Type == EventEvicted {
+			assert.Equal(t, "soon", event.Key)
+			break
+		}
+	}
+	subscription.Close()
+}
+
+func TestCache_SetWeigher(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetWithTTL("soon", []byte("12345"), time.Second)
+	cache.SetWithTTL("later", []byte("1234"), time.Minute)
+	cache.SetWeigher(func(key string, value interface{}) int64 {
+		return int64(len(value.([]byte)))
+	}, 10)
+	assert.Equal(t, int64(9), cache.GetMetrics().Cost)
+
+	cache.SetWithTTL("latest", []byte("123"), time.Hour)
+	assert.False(t, cache.Has("soon"), "Expected the item closest to expiration to be evicted")
+	assert.Equal(t, int64(7), cache.GetMetrics().Cost)
+	cache.SetWithTTL("later", []byte("1"), time.Minute)
+	assert.Equal(t, int64(4), cache.GetMetrics().Cost, "Expected an update to replace the cost")
+	cache.Remove("latest")
+	assert.Equal(t, int64(1), cache.GetMetrics().Cost)
+	meta, _ := cache.GetItemMeta("later")
+	assert.Equal(t, int64(1), meta.Cost)
+
+	_, err := NewCacheFromConfig(Config{MaxCost: 100})
+	assert.NotNil(t, err, "Expected maxCost to need a weigher")
+}
+
+func TestCache_SetWeigherOversizedItem(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	added := 0
+	cache.SetNewItemCallback(func(key string, value interface{}) {
+		added++
+	})
+	cache.SetWeigher(func(key string, value interface{}) int64 {
+		return int64(len(value.(string)))
+	}, 10)
+	cache.SetWithTTL("small", "1234", time.Hour)
+	cache.SetWithTTL("replaced", "1234", time.Hour)
+
+	cache.SetWithTTL("huge", "12345678901", time.Hour)
+	assert.False(t, cache.Has("huge"), "Expected an item over the cost limit not to be stored")
+	assert.True(t, cache.Has("small"), "Expected an item over the cost limit not to evict others")
+	cache.SetWithTTL("replaced", "12345678901", time.Hour)
+	assert.False(t, cache.Has("replaced"), "Expected an oversized write to drop the old value")
+	assert.Equal(t, 2, added, "Expected no new item callback for rejected items")
+	assert.Equal(t, int64(4), cache.GetMetrics().Cost)
+
+	cache.SetWithTTL("soonest", "123456789", time.Second)
+	assert.True(t, cache.Has("soonest"), "Expected the item being written not to evict itself")
+	assert.False(t, cache.Has("small"))
+	assert.NoError(t, cache.VerifyIntegrity())
+}
+
+func TestCache_SetTTLJitter(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetTTLJitter(0.5)
+	cache.SetWithTTL("key", "value", time.Hour)
+	_, expireAt, _ := cache.peek("key")
+	remaining := time.Until(expireAt)
+	assert.True(t, remaining > 29*time.Minute && remaining <= time.Hour, "Expected the TTL to be shortened by at most half")
+}
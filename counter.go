@@ -0,0 +1,105 @@
+package ttlcache
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	// ErrNotInteger is returned by Increment and Decrement when the value of the key is not an int, int32 or int64
+	ErrNotInteger = errors.New("ttlcache: value is not an integer")
+	// ErrOverflow is returned by Increment and Decrement when the result does not fit the type of the value
+	ErrOverflow = errors.New("ttlcache: integer overflow")
+)
+
+// Increment adds delta to the integer value of the key under the lock of the cache and returns the result, for counters
+// and rate limits. A missing key is stored as an int64 delta with the global TTL. The TTL of an existing key is not
+// restarted, so a counter with a TTL counts per fixed window, see IncrementWithTTL for the other case. Values of type
+// int, int32 and int64 keep their type, a result which does not fit it is not stored and gives ErrOverflow. The value
+// is changed in place, the middleware does not see it, see Use.
+func (cache *CacheOf[K]) Increment(key K, delta int64) (int64, error) {
+	return cache.increment(key, delta, ItemExpireWithGlobalTTL, false)
+}
+
+// Decrement subtracts delta from the integer value of the key, see Increment. A delta of math.MinInt64 cannot be
+// negated and gives ErrOverflow.
+func (cache *CacheOf[K]) Decrement(key K, delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	return cache.increment(key, -delta, ItemExpireWithGlobalTTL, false)
+}
+
+// IncrementWithTTL adds delta to the integer value of the key like Increment, and gives the item the TTL, starting
+// now, whether it existed or not. A negative delta decrements.
+func (cache *CacheOf[K]) IncrementWithTTL(key K, delta int64, ttl time.Duration) (int64, error) {
+	return cache.increment(key, delta, ttl, true)
+}
+
+// increment adds delta to the value of the key, restarting its TTL when retime is set
+func (cache *CacheOf[K]) increment(key K, delta int64, ttl time.Duration, retime bool) (int64, error) {
+	cache.mutex.Lock()
+	if cache.isShutDown {
+		cache.mutex.Unlock()
+		return 0, ErrCacheClosed
+	}
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		key, isNew := cache.set(key, delta, ttl, itemOptions[K]{})
+		newItemCallback := cache.newItemCallback
+		cache.mutex.Unlock()
+		if isNew && newItemCallback != nil {
+			newItemCallback(key, delta)
+		}
+		cache.notifyExpiration()
+		return delta, nil
+	}
+
+	var result int64
+	var data interface{}
+	err := ErrOverflow
+	switch value := item.Data.(type) {
+	case int:
+		if result = int64(value) + delta; sumFits(int64(value), delta, result) && result >= math.MinInt &&
+			result <= math.MaxInt {
+			data, err = int(result), nil
+		}
+	case int32:
+		if result = int64(value) + delta; sumFits(int64(value), delta, result) && result >= math.MinInt32 &&
+			result <= math.MaxInt32 {
+			data, err = int32(result), nil
+		}
+	case int64:
+		if result = value + delta; sumFits(value, delta, result) {
+			data, err = result, nil
+		}
+	default:
+		err = ErrNotInteger
+	}
+	if err != nil {
+		cache.mutex.Unlock()
+		return 0, err
+	}
+	oldData := item.Data
+	item.Data = data
+	item.version = cache.nextVersion()
+	cache.invalidateReads()
+	if retime {
+		cache.retime(item, ttl)
+	}
+	if cache.observer != nil {
+		cache.observer.itemUpdated(item, oldData, item.weight)
+	}
+	cache.publish(EventUpdated, item.key, data)
+	cache.mutex.Unlock()
+	if retime {
+		cache.notifyExpiration()
+	}
+	return result, nil
+}
+
+// sumFits reports whether sum is value plus delta without an int64 overflow
+func sumFits(value, delta, sum int64) bool {
+	return (delta >= 0) == (sum >= value)
+}
@@ -0,0 +1,99 @@
+package ttlcache
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Increment(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	value, err := cache.Increment("hits", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), value, "Expected a missing key to start at delta")
+	value, err = cache.Decrement("hits", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-3), value)
+
+	cache.Set("int", 10)
+	value, err = cache.Increment("int", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), value)
+	data, _ := cache.Get("int")
+	assert.Equal(t, 11, data, "Expected the value to keep its type")
+
+	cache.Set("text", "10")
+	_, err = cache.Increment("text", 1)
+	assert.Equal(t, ErrNotInteger, err)
+}
+
+func TestCache_IncrementOverflow(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("int32", int32(math.MaxInt32))
+	cache.Set("int64", int64(math.MinInt64))
+
+	_, err := cache.Increment("int32", 1)
+	assert.Equal(t, ErrOverflow, err)
+	data, _ := cache.Get("int32")
+	assert.Equal(t, int32(math.MaxInt32), data, "Expected an overflow not to be stored")
+	_, err = cache.Decrement("int64", 1)
+	assert.Equal(t, ErrOverflow, err)
+	data, _ = cache.Get("int64")
+	assert.Equal(t, int64(math.MinInt64), data)
+
+	value, err := cache.Decrement("int32", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt32-1), value)
+
+	cache.Set("zero", int64(0))
+	_, err = cache.Decrement("zero", math.MinInt64)
+	assert.Equal(t, ErrOverflow, err, "Expected a delta which cannot be negated to fail")
+	data, _ = cache.Get("zero")
+	assert.Equal(t, int64(0), data)
+}
+
+func TestCache_IncrementConcurrent(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = cache.Increment("counter", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	data, _ := cache.Get("counter")
+	assert.Equal(t, int64(1000), data, "Expected no increment to be lost")
+}
+
+func TestCache_IncrementWithTTL(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("window", int64(1), time.Minute)
+	before, _ := cache.GetExpireAt("window")
+	time.Sleep(10 * time.Millisecond)
+
+	_, _ = cache.Increment("window", 1)
+	unchanged, _ := cache.GetExpireAt("window")
+	assert.True(t, unchanged.Equal(before), "Expected Increment to keep the TTL running")
+
+	value, err := cache.IncrementWithTTL("window", 1, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+	ttl, _ := cache.GetTTL("window")
+	assert.Equal(t, time.Hour, ttl, "Expected IncrementWithTTL to restart the TTL")
+
+	cache.Close()
+	_, err = cache.Increment("window", 1)
+	assert.Equal(t, ErrCacheClosed, err)
+}
@@ -0,0 +1,38 @@
+package ttlcache
+
+import "time"
+
+// DefaultCacheName is the name of the default cache in the DefaultRegistry
+const DefaultCacheName = "default"
+
+// Default returns the package level cache used by Set, Get, Remove and SetDefaultTTL. It is created on first use
+// and registered in the DefaultRegistry, so CloseAll closes it too. Convenient for scripts and small services,
+// larger programs are better off passing a *Cache around.
+func Default() *Cache {
+	return DefaultRegistry.GetOrCreate(DefaultCacheName)
+}
+
+// Set adds an item to the default cache with the global TTL
+func Set(key string, data interface{}) {
+	Default().Set(key, data)
+}
+
+// SetWithTTL adds an item to the default cache with an individual TTL
+func SetWithTTL(key string, data interface{}, ttl time.Duration) {
+	Default().SetWithTTL(key, data, ttl)
+}
+
+// Get looks up an item in the default cache
+func Get(key string) (interface{}, bool) {
+	return Default().Get(key)
+}
+
+// Remove removes an item from the default cache
+func Remove(key string) bool {
+	return Default().Remove(key)
+}
+
+// SetDefaultTTL sets the global TTL of the default cache
+func SetDefaultTTL(ttl time.Duration) {
+	Default().SetTTL(ttl)
+}
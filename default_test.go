@@ -0,0 +1,25 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	defer CloseAll()
+
+	SetDefaultTTL(50 * time.Millisecond)
+	Set("key", "value")
+	SetWithTTL("long", "value", time.Minute)
+	data, exists := Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	assert.Equal(t, Default(), GetOrCreate(DefaultCacheName))
+
+	<-time.After(100 * time.Millisecond)
+	_, exists = Get("key")
+	assert.False(t, exists, "Expected the default TTL to apply")
+	assert.True(t, Remove("long"))
+}
@@ -0,0 +1,175 @@
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType tells what happened to an Item
+type EventType int
+
+const (
+	// EventInserted is sent when a key is added to the cache
+	EventInserted EventType = iota
+	// EventUpdated is sent when the value of an existing key is replaced
+	EventUpdated
+	// EventExpired is sent when an Item is evicted because it expired
+	EventExpired
+	// EventRemoved is sent when an Item is removed explicitly
+	EventRemoved
+	// EventEvicted is sent when an Item is removed to respect the size limit of the cache
+	EventEvicted
+	// EventPurged is sent for every Item removed by Purge
+	EventPurged
+)
+
+var eventTypeNames = [...]string{"insert", "update", "expire", "remove", "evict", "purge"}
+
+func (t EventType) String() string {
+	if t < 0 || int(t) >= len(eventTypeNames) {
+		return "unknown"
+	}
+	return eventTypeNames[t]
+}
+
+// Event describes a change of the content of a Cache
+type Event = EventOf[string]
+
+// EventOf describes a change of the content of a CacheOf with keys of type K
+type EventOf[K comparable] struct {
+	Type  EventType
+	Key   K
+	Value interface{}
+	Time  time.Time
+}
+
+// OverflowPolicy tells what happens to an event when the buffer of a subscription is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the new event, the subscription keeps the oldest undelivered events
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest undelivered event to make room for the new one
+	OverflowDropOldest
+	// OverflowBlock waits until the subscriber receives the event. All cache operations wait with it,
+	// so the subscriber must keep receiving and must not call the cache while doing so.
+	OverflowBlock
+)
+
+// Subscription receives the events of a Cache
+type Subscription = SubscriptionOf[string]
+
+// SubscriptionOf receives the events of a cache on C until it is closed, or until the cache is closed.
+type SubscriptionOf[K comparable] struct {
+	// C delivers the events in the order they happened
+	C <-chan EventOf[K]
+
+	events   chan EventOf[K]
+	cache    *CacheOf[K]
+	overflow OverflowPolicy
+	dropped  int64
+	// closing unblocks a publish waiting with OverflowBlock when the subscription is closed
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe returns a Subscription receiving all future events. Events are delivered without blocking the cache,
+// when the buffer of the subscription is full they are dropped, see Dropped.
+// Close the subscription when it is no longer used.
+func (cache *CacheOf[K]) Subscribe(buffer int) *SubscriptionOf[K] {
+	return cache.SubscribeWithOverflow(buffer, OverflowDropNewest)
+}
+
+// SubscribeWithOverflow returns a Subscription like Subscribe, handling a full buffer with the given policy
+func (cache *CacheOf[K]) SubscribeWithOverflow(buffer int, overflow OverflowPolicy) *SubscriptionOf[K] {
+	events := make(chan EventOf[K], buffer)
+	subscription := &SubscriptionOf[K]{
+		C:        events,
+		events:   events,
+		cache:    cache,
+		overflow: overflow,
+		closing:  make(chan struct{}),
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.isShutDown {
+		close(events)
+		return subscription
+	}
+	if cache.subscriptions == nil {
+		cache.subscriptions = make(map[*SubscriptionOf[K]]struct{})
+	}
+	cache.subscriptions[subscription] = struct{}{}
+	return subscription
+}
+
+// Close stops the delivery of events and closes C
+func (subscription *SubscriptionOf[K]) Close() {
+	subscription.closeOnce.Do(func() { close(subscription.closing) })
+	cache := subscription.cache
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if _, exists := cache.subscriptions[subscription]; exists {
+		delete(cache.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// Dropped returns the number of events which were dropped because the buffer was full, the dropped events are
+// the newest or the oldest ones depending on the OverflowPolicy
+func (subscription *SubscriptionOf[K]) Dropped() int64 {
+	subscription.cache.mutex.Lock()
+	defer subscription.cache.mutex.Unlock()
+	return subscription.dropped
+}
+
+// publish hands an event to all subscriptions, the cache mutex must be held
+func (cache *CacheOf[K]) publish(eventType EventType, key K, value interface{}) {
+	if len(cache.subscriptions) == 0 {
+		return
+	}
+	event := EventOf[K]{Type: eventType, Key: key, Value: value, Time: cache.clock.Now()}
+	for subscription := range cache.subscriptions {
+		subscription.deliver(event)
+	}
+}
+
+// deliver sends the event according to the overflow policy, the cache mutex must be held
+func (subscription *SubscriptionOf[K]) deliver(event EventOf[K]) {
+	select {
+	case subscription.events <- event:
+		return
+	default:
+	}
+
+	switch subscription.overflow {
+	case OverflowDropOldest:
+		// the subscriber may receive concurrently, so neither step can block
+		select {
+		case <-subscription.events:
+			subscription.dropped++
+		default:
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			subscription.dropped++
+		}
+	case OverflowBlock:
+		select {
+		case subscription.events <- event:
+		case <-subscription.closing:
+		}
+	default:
+		subscription.dropped++
+	}
+}
+
+// closeSubscriptions closes all subscriptions, the cache mutex must be held
+func (cache *CacheOf[K]) closeSubscriptions() {
+	for subscription := range cache.subscriptions {
+		close(subscription.events)
+	}
+	cache.subscriptions = nil
+}
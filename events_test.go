@@ -0,0 +1,126 @@
+package ttlcache
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Subscribe(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	subscription := cache.Subscribe(10)
+	cache.Set("key", "value")
+	cache.Set("key", "value2")
+	cache.SetWithTTL("short", "value", 10*time.Millisecond)
+	cache.Remove("key")
+
+	expected := []struct {
+		eventType EventType
+		key       string
+	}{{EventInserted, "key"}, {EventUpdated, "key"}, {EventInserted, "short"}, {EventRemoved, "key"}, {EventExpired, "short"}}
+	for _, e := range expected {
+		select {
+		case event := <-subscription.C:
+			assert.Equal(t, e.eventType, event.Type)
+			assert.Equal(t, e.key, event.Key)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a %s event for %s", e.eventType, e.key)
+		}
+	}
+
+	subscription.Close()
+	_, open := <-subscription.C
+	assert.False(t, open, "Expected the channel to be closed")
+	subscription.Close()
+}
+
+func TestCache_SubscribeDropsWhenFull(t *testing.T) {
+	cache := NewCache()
+
+	subscription := cache.Subscribe(1)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	assert.Equal(t, int64(2), subscription.Dropped())
+
+	cache.Close()
+	event := <-subscription.C
+	assert.Equal(t, "a", event.Key)
+	_, open := <-subscription.C
+	assert.False(t, open, "Expected Close of the cache to close subscriptions")
+	subscription.Close()
+}
+
+func TestCache_SubscribeWithOverflow(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	oldest := cache.SubscribeWithOverflow(1, OverflowDropOldest)
+	defer oldest.Close()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	assert.Equal(t, int64(2), oldest.Dropped())
+	event := <-oldest.C
+	assert.Equal(t, "c", event.Key, "Expected the newest event to be kept")
+
+	blocking := cache.SubscribeWithOverflow(0, OverflowBlock)
+	received := make(chan string)
+	go func() {
+		for event := range blocking.C {
+			received <- event.Key
+		}
+		close(received)
+	}()
+	go cache.Set("d", 4)
+	assert.Equal(t, "d", <-received)
+	assert.Equal(t, int64(0), blocking.Dropped())
+
+	blocking.Close()
+	for range received {
+	}
+
+	// closing the subscription releases a blocked writer
+	unread := cache.SubscribeWithOverflow(0, OverflowBlock)
+	written := make(chan struct{})
+	go func() {
+		cache.Set("e", 5)
+		close(written)
+	}()
+	// the writer holds the lock of the cache while it waits for the subscriber
+	for cache.mutex.TryLock() {
+		cache.mutex.Unlock()
+		runtime.Gosched()
+	}
+	unread.Close()
+	<-written
+}
+
+func TestCache_PurgeEvents(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	reasons := make(chan EventType, 2)
+	cache.SetRemovalCallback(func(key string, value interface{}, reason EventType) {
+		reasons <- reason
+	})
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	subscription := cache.Subscribe(10)
+	defer subscription.Close()
+	cache.Purge()
+
+	keys := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		event := <-subscription.C
+		assert.Equal(t, EventPurged, event.Type)
+		keys[event.Key] = true
+		assert.Equal(t, EventPurged, <-reasons)
+	}
+	assert.Equal(t, map[string]bool{"a": true, "b": true}, keys)
+	assert.Equal(t, "purge", EventPurged.String())
+}
@@ -1,10 +1,14 @@
 module github.com/jadevelopmentgrp/TTLCache
 
-go 1.14
+go 1.18
 
 require (
 	github.com/ReneKroon/ttlcache v1.6.0
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/stretchr/testify v1.3.0
 	go.uber.org/goleak v0.10.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
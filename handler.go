@@ -0,0 +1,209 @@
+package ttlcache
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	debugTopKeys       = 20
+	debugSearchLimit   = 100
+	debugValueMaxWidth = 120
+)
+
+// debugBucketBounds are the upper bounds of the remaining TTL histogram shown on the debug page
+var debugBucketBounds = []struct {
+	label string
+	bound time.Duration
+}{
+	{"< 1s", time.Second},
+	{"< 10s", 10 * time.Second},
+	{"< 1m", time.Minute},
+	{"< 10m", 10 * time.Minute},
+	{"< 1h", time.Hour},
+}
+
+type debugEntry struct {
+	Key       string
+	Value     string
+	Hits      int64
+	Remaining string
+
+	data interface{}
+}
+
+type debugBucket struct {
+	Label   string
+	Count   int
+	Percent int
+}
+
+type debugPage struct {
+	Metrics   Metrics
+	Count     int
+	TTL       time.Duration
+	TopKeys   []debugEntry
+	Histogram []debugBucket
+	Query     string
+	Matches   []debugEntry
+	Truncated bool
+}
+
+// Handler returns an http.Handler serving a read only debug page for the cache, in the spirit of net/http/pprof.
+// The page shows the metrics, the most requested keys, a histogram of the remaining TTLs and offers a search on keys.
+// Mount it on a path of your choosing, for example:
+//
+//	http.Handle("/debug/ttlcache/", http.StripPrefix("/debug/ttlcache", cache.Handler()))
+func (cache *Cache) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := cache.debugSnapshot(r.URL.Query().Get("q"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := debugTemplate.Execute(w, page); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// debugSnapshot gathers everything shown on the debug page under a single lock. Values are formatted
+// after the lock is released, so a String method on a value may safely use the cache.
+func (cache *Cache) debugSnapshot(query string) debugPage {
+	page := debugPage{Query: query}
+	counts := make([]int, len(debugBucketBounds)+3)
+	var all []debugEntry
+
+	cache.mutex.Lock()
+	now := cache.clock.Now()
+	page.Metrics = cache.countedMetrics()
+	page.Count = cache.items.Len()
+	page.TTL = cache.ttl
+	cache.items.Range(func(key string, item *Item) bool {
+		entry := debugEntry{Key: key, Hits: item.hitCount(), data: item.Data, Remaining: "never"}
+		if item.TTL > 0 {
+			remaining := item.ExpireAt.Sub(now)
+			entry.Remaining = remaining.Round(time.Millisecond).String()
+			counts[debugBucketIndex(remaining)]++
+		} else {
+			counts[len(counts)-1]++
+		}
+		all = append(all, entry)
+		return true
+	})
+	cache.mutex.Unlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Hits != all[j].Hits {
+			return all[i].Hits > all[j].Hits
+		}
+		return all[i].Key < all[j].Key
+	})
+	if len(all) > debugTopKeys {
+		page.TopKeys = append(page.TopKeys, all[:debugTopKeys]...)
+	} else {
+		page.TopKeys = append(page.TopKeys, all...)
+	}
+	formatDebugValues(page.TopKeys)
+
+	if query != "" {
+		for _, entry := range all {
+			if !strings.Contains(entry.Key, query) {
+				continue
+			}
+			if len(page.Matches) == debugSearchLimit {
+				page.Truncated = true
+				break
+			}
+			page.Matches = append(page.Matches, entry)
+		}
+		sort.Slice(page.Matches, func(i, j int) bool { return page.Matches[i].Key < page.Matches[j].Key })
+		formatDebugValues(page.Matches)
+	}
+
+	labels := []string{"expired"}
+	for _, b := range debugBucketBounds {
+		labels = append(labels, b.label)
+	}
+	labels = append(labels, "≥ 1h", "no expiry")
+	for i, count := range counts {
+		bucket := debugBucket{Label: labels[i], Count: count}
+		if page.Count > 0 {
+			bucket.Percent = count * 100 / page.Count
+		}
+		page.Histogram = append(page.Histogram, bucket)
+	}
+	return page
+}
+
+// debugBucketIndex returns the histogram slot for a remaining TTL, slot 0 holds already expired items
+func debugBucketIndex(remaining time.Duration) int {
+	if remaining <= 0 {
+		return 0
+	}
+	for i, b := range debugBucketBounds {
+		if remaining < b.bound {
+			return i + 1
+		}
+	}
+	return len(debugBucketBounds) + 1
+}
+
+func formatDebugValues(entries []debugEntry) {
+	for i := range entries {
+		value := fmt.Sprintf("%v", entries[i].data)
+		if len(value) > debugValueMaxWidth {
+			value = value[:debugValueMaxWidth] + "…"
+		}
+		entries[i].Value = value
+	}
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ttlcache</title>
+<style>
+body { font-family: monospace; margin: 1em 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { padding: 2px 10px; text-align: left; border-bottom: 1px solid #ddd; }
+.bar { background: #4a90d9; height: 0.8em; display: inline-block; }
+</style>
+</head>
+<body>
+<h1>ttlcache</h1>
+<p><a href="?q={{.Query}}">refresh</a></p>
+<h2>Stats</h2>
+<table>
+<tr><th>items</th><td>{{.Count}}</td></tr>
+<tr><th>global TTL</th><td>{{.TTL}}</td></tr>
+<tr><th>inserted</th><td>{{.Metrics.Inserted}}</td></tr>
+<tr><th>retrievals</th><td>{{.Metrics.Retrievals}}</td></tr>
+<tr><th>hits</th><td>{{.Metrics.Hits}}</td></tr>
+<tr><th>misses</th><td>{{.Metrics.Misses}}</td></tr>
+<tr><th>evicted</th><td>{{.Metrics.Evicted}}</td></tr>
+<tr><th>expiration queue</th><td>{{.Metrics.QueueLength}} (max {{.Metrics.QueueMaxLength}})</td></tr>
+<tr><th>queue operations/s</th><td>{{printf "%.1f" .Metrics.QueuePushRate}} pushes, {{printf "%.1f" .Metrics.QueuePopRate}} pops, {{printf "%.1f" .Metrics.QueueRemovalRate}} removals, {{printf "%.1f" .Metrics.QueueFixRate}} fixes</td></tr>
+</table>
+<h2>Remaining TTL</h2>
+<table>
+{{range .Histogram}}<tr><th>{{.Label}}</th><td>{{.Count}}</td><td><span class="bar" style="width: {{.Percent}}px"></span></td></tr>
+{{end}}</table>
+<h2>Top keys</h2>
+<table>
+<tr><th>key</th><th>hits</th><th>remaining</th><th>value</th></tr>
+{{range .TopKeys}}<tr><td>{{.Key}}</td><td>{{.Hits}}</td><td>{{.Remaining}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+<h2>Search</h2>
+<form method="get"><input type="text" name="q" value="{{.Query}}" placeholder="key contains"> <input type="submit" value="search"></form>
+{{if .Query}}<table>
+<tr><th>key</th><th>hits</th><th>remaining</th><th>value</th></tr>
+{{range .Matches}}<tr><td>{{.Key}}</td><td>{{.Hits}}</td><td>{{.Remaining}}</td><td>{{.Value}}</td></tr>
+{{else}}<tr><td colspan="4">no matching keys</td></tr>
+{{end}}</table>
+{{if .Truncated}}<p>only the first matches are shown, refine the search</p>{{end}}{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,87 @@
+package ttlcache
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Handler(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetWithTTL("user:1", "alice", time.Minute)
+	cache.SetWithTTL("user:2", "bob", time.Minute)
+	cache.Set("config", "<script>")
+	cache.Get("user:2")
+	cache.Get("user:2")
+	cache.Get("missing")
+
+	recorder := httptest.NewRecorder()
+	cache.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	body := recorder.Body.String()
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, body, "<tr><th>hits</th><td>2</td></tr>")
+	assert.Contains(t, body, "<tr><th>misses</th><td>1</td></tr>")
+	assert.Contains(t, body, "<tr><td>user:2</td><td>2</td>", "Expected the most requested key in the top keys")
+	assert.Contains(t, body, "&lt;script&gt;", "Expected values to be escaped")
+	assert.Contains(t, body, "<tr><th>&lt; 1m</th><td>2</td>")
+	assert.Contains(t, body, "<tr><th>no expiry</th><td>1</td>")
+
+	recorder = httptest.NewRecorder()
+	cache.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/?q=user:", nil))
+	body = recorder.Body.String()
+	assert.Contains(t, body, "<tr><td>user:1</td><td>0</td>")
+	assert.NotContains(t, body, "no matching keys")
+
+	recorder = httptest.NewRecorder()
+	cache.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/?q=nope", nil))
+	assert.Contains(t, recorder.Body.String(), "no matching keys")
+}
+
+func TestCache_GetMetrics(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetWithTTL("short", "value", time.Millisecond*20)
+	cache.Set("key", "value")
+	cache.Set("key", "value2")
+	cache.Get("key")
+	cache.Get("other")
+	<-time.After(100 * time.Millisecond)
+
+	metrics := cache.GetMetrics()
+	assert.Equal(t, int64(2), metrics.Inserted)
+	assert.Equal(t, int64(2), metrics.Retrievals)
+	assert.Equal(t, int64(1), metrics.Hits)
+	assert.Equal(t, int64(1), metrics.Misses)
+	assert.Equal(t, int64(1), metrics.Evicted)
+}
+
+func TestCache_GetMetricsQueue(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Set("a", 4)
+	cache.Remove("c")
+
+	metrics := cache.GetMetrics()
+	assert.Equal(t, 2, metrics.QueueLength)
+	assert.Equal(t, 3, metrics.QueueMaxLength)
+	assert.Equal(t, int64(3), metrics.QueuePushes)
+	assert.Equal(t, int64(0), metrics.QueuePops)
+	assert.Equal(t, int64(1), metrics.QueueRemovals, "Expected Remove to count as a removal, not as a pop")
+	assert.Equal(t, int64(1), metrics.QueueFixes)
+
+	cache.Purge()
+	metrics = cache.GetMetrics()
+	assert.Equal(t, 0, metrics.QueueLength)
+	assert.Equal(t, 3, metrics.QueueMaxLength, "Expected Purge to keep the counters")
+	assert.Equal(t, int64(3), metrics.QueuePushes)
+}
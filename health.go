@@ -0,0 +1,41 @@
+package ttlcache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// expirationStallTolerance is how late the expiration goroutine may be before the cache is considered unhealthy
+const expirationStallTolerance = 5 * time.Second
+
+var (
+	// ErrCacheClosed is returned when the cache is used after Close
+	ErrCacheClosed = errors.New("ttlcache: cache is closed")
+	// ErrExpirationStalled is returned by Healthy when expired items are no longer being removed
+	ErrExpirationStalled = errors.New("ttlcache: expiration processing stalled")
+)
+
+// Healthy verifies that the cache is open, that the expiration goroutine did not miss its last wakeup, for instance
+// because a callback blocks it, and that the last snapshot upload started with StartSnapshotUploads succeeded.
+// It is meant to back a liveness probe such as /healthz. Failed loads do not count: the loader has no circuit breaker,
+// only a RetryPolicy, and its errors are returned to the callers of GetOrLoad.
+func (cache *CacheOf[K]) Healthy() error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.isShutDown {
+		return ErrCacheClosed
+	}
+	if late := cache.clock.Now().Sub(cache.expirationTime); !cache.expirationDisabled && late > expirationStallTolerance {
+		return fmt.Errorf("%w: last sweep at %s, %s overdue", ErrExpirationStalled, cache.lastSweep.Format(time.RFC3339), late.Round(time.Second))
+	}
+	if cache.snapshotErr != nil {
+		return fmt.Errorf("ttlcache: last snapshot upload failed: %w", cache.snapshotErr)
+	}
+	return nil
+}
+
+// Ready reports whether the cache can serve traffic, meant to back a readiness probe
+func (cache *CacheOf[K]) Ready() bool {
+	return cache.Healthy() == nil
+}
@@ -0,0 +1,41 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingSnapshotStorage struct {
+	memorySnapshotStorage
+}
+
+func (storage *failingSnapshotStorage) Put(ctx context.Context, snapshot io.Reader) error {
+	return errors.New("read-only file system")
+}
+
+func TestCache_Healthy(t *testing.T) {
+	cache := NewCache()
+	assert.Nil(t, cache.Healthy())
+	assert.True(t, cache.Ready())
+
+	cache.mutex.Lock()
+	cache.expirationTime = time.Now().Add(-time.Minute)
+	cache.mutex.Unlock()
+	assert.True(t, errors.Is(cache.Healthy(), ErrExpirationStalled))
+	assert.False(t, cache.Ready())
+	cache.SetTTL(time.Hour)
+	<-time.After(10 * time.Millisecond)
+	assert.Nil(t, cache.Healthy(), "Expected the cache to recover once the expiration goroutine runs")
+
+	cache.StartSnapshotUploads(&failingSnapshotStorage{}, 10*time.Millisecond, nil)
+	<-time.After(50 * time.Millisecond)
+	assert.Contains(t, cache.Healthy().Error(), "read-only file system")
+
+	cache.Close()
+	assert.Equal(t, ErrCacheClosed, cache.Healthy())
+}
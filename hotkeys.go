@@ -0,0 +1,130 @@
+package ttlcache
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// HotKeyRefresh configures the background refresh of the most used keys
+type HotKeyRefresh struct {
+	// TopK is the number of keys with the most recent hits that are refreshed, zero disables the refresh
+	TopK int
+	// RefreshAhead is the fraction of the TTL before the expiration at which a hot key is reloaded, for instance 0.1
+	// reloads an item with a TTL of a minute in its last 6 seconds
+	RefreshAhead float64
+	// Interval is how often the hot keys are checked, it defaults to a second
+	Interval time.Duration
+}
+
+// SetHotKeyRefresh reloads the TopK keys with the most recent hits with the loader shortly before they expire, so the
+// hottest keys do not miss in steady state. This matters when hits do not extend the TTL, see SkipTtlExtensionOnHit.
+// Calling it again replaces the configuration.
+func (cache *CacheOf[K]) SetHotKeyRefresh(refresh HotKeyRefresh) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.hotKeysStop != nil {
+		close(cache.hotKeysStop)
+		cache.hotKeysStop = nil
+	}
+	if refresh.TopK <= 0 || cache.isShutDown {
+		return
+	}
+	if refresh.Interval <= 0 {
+		refresh.Interval = time.Second
+	}
+	stop := make(chan struct{})
+	cache.hotKeysStop = stop
+	cache.workers.Add(1)
+	go cache.refreshHotKeys(refresh, stop)
+}
+
+// refreshHotKeys reloads the hot keys due for a refresh on every tick until stop or the cache is closed
+func (cache *CacheOf[K]) refreshHotKeys(refresh HotKeyRefresh, stop chan struct{}) {
+	defer cache.workers.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ticker := time.NewTicker(refresh.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-cache.done:
+			return
+		case <-ticker.C:
+		}
+
+		cache.mutex.Lock()
+		loader, policy := cache.loader, cache.retryPolicy
+		keys := cache.dueHotKeys(refresh, cache.clock.Now())
+		cache.mutex.Unlock()
+		if loader == nil {
+			continue
+		}
+		for _, key := range keys {
+			select {
+			case <-stop:
+				return
+			case <-cache.done:
+				return
+			default:
+			}
+			if result, err := cache.load(ctx, key, loader, policy); err == nil {
+				cache.storeLoaded(key, result)
+			}
+		}
+	}
+}
+
+// dueHotKeys returns the keys among the TopK with the highest hot score that expire within the refresh ahead part of
+// their TTL, the cache mutex must be held. The score halves on every call and adds the hits since the previous one, so
+// keys which were hot long ago give way to the ones hot now. Only the TopK items are kept while the items are visited.
+func (cache *CacheOf[K]) dueHotKeys(refresh HotKeyRefresh, now time.Time) []K {
+	hot := make(hotItems[K], 0, refresh.TopK)
+	cache.items.Range(func(_ K, item *ItemOf[K]) bool {
+		hits := item.hitCount()
+		if hits < item.hotSeen {
+			item.hotSeen = 0
+		}
+		item.hotScore = item.hotScore/2 + float64(hits-item.hotSeen)
+		item.hotSeen = hits
+		if item.TTL <= 0 || item.hotScore == 0 {
+			return true
+		}
+		if len(hot) < refresh.TopK {
+			heap.Push(&hot, item)
+		} else if item.hotScore > hot[0].hotScore {
+			hot[0] = item
+			heap.Fix(&hot, 0)
+		}
+		return true
+	})
+
+	var due []K
+	for _, item := range hot {
+		ahead := time.Duration(refresh.RefreshAhead * float64(item.TTL))
+		if !item.expiredAt(now) && item.ExpireAt.Sub(now) <= ahead {
+			due = append(due, item.key)
+		}
+	}
+	return due
+}
+
+// hotItems is a min-heap of items by their hot score, the root is the coldest of the hot keys
+type hotItems[K comparable] []*ItemOf[K]
+
+func (hot hotItems[K]) Len() int           { return len(hot) }
+func (hot hotItems[K]) Less(i, j int) bool { return hot[i].hotScore < hot[j].hotScore }
+func (hot hotItems[K]) Swap(i, j int)      { hot[i], hot[j] = hot[j], hot[i] }
+
+func (hot *hotItems[K]) Push(x interface{}) {
+	*hot = append(*hot, x.(*ItemOf[K]))
+}
+
+func (hot *hotItems[K]) Pop() interface{} {
+	old := *hot
+	item := old[len(old)-1]
+	*hot = old[:len(old)-1]
+	return item
+}
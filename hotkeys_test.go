@@ -0,0 +1,67 @@
+package ttlcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetHotKeyRefresh(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	refreshed := make(chan string, 10)
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		refreshed <- key
+		return "fresh", 100 * time.Millisecond, nil
+	})
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetWithTTL("hot", "stale", 100*time.Millisecond)
+	cache.SetWithTTL("cold", "stale", 100*time.Millisecond)
+	cache.Get("hot")
+	cache.Get("hot")
+	cache.SetHotKeyRefresh(HotKeyRefresh{TopK: 1, RefreshAhead: 0.5, Interval: 10 * time.Millisecond})
+
+	select {
+	case key := <-refreshed:
+		assert.Equal(t, "hot", key)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the hot key to be refreshed")
+	}
+	data, exists := cache.Get("hot")
+	assert.True(t, exists)
+	assert.Equal(t, "fresh", data)
+
+	cache.SetHotKeyRefresh(HotKeyRefresh{})
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, cache.Has("cold"), "Expected the cold key to expire")
+	for len(refreshed) > 0 {
+		assert.Equal(t, "hot", <-refreshed)
+	}
+}
+
+func TestCache_DueHotKeysDecay(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetWithTTL("past", "value", time.Minute)
+	cache.SetWithTTL("now", "value", time.Minute)
+	for i := 0; i < 8; i++ {
+		cache.Get("past")
+	}
+	refresh := HotKeyRefresh{TopK: 1, RefreshAhead: 1}
+	cache.mutex.Lock()
+	assert.Equal(t, []string{"past"}, cache.dueHotKeys(refresh, time.Now()))
+	cache.mutex.Unlock()
+
+	for i := 0; i < 5; i++ {
+		cache.Get("now")
+	}
+	cache.mutex.Lock()
+	assert.Equal(t, []string{"now"}, cache.dueHotKeys(refresh, time.Now()),
+		"Expected the recent hits to outweigh the decayed ones")
+	cache.mutex.Unlock()
+}
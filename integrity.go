@@ -0,0 +1,46 @@
+package ttlcache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrIntegrity is returned by VerifyIntegrity when the internal structures of the cache are inconsistent
+var ErrIntegrity = errors.New("ttlcache: integrity check failed")
+
+// VerifyIntegrity checks that the map of items and the expiration queue agree: every queued item is in the map under
+// its key and knows its index, the queue is ordered, and no item stays expired for longer than the sweeps may lag.
+// It locks the cache for the whole check and is meant for tests and fuzzing of applications embedding the cache.
+func (cache *CacheOf[K]) VerifyIntegrity() error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	queue := cache.priorityQueue
+	if queue == nil {
+		// without expiration there is only the map
+		return nil
+	}
+	if cache.items.Len() != queue.Len() {
+		return fmt.Errorf("%w: %d items in the map, %d in the queue", ErrIntegrity, cache.items.Len(), queue.Len())
+	}
+	limit := cache.clock.Now().Add(-expirationStallTolerance)
+	for i, item := range queue.items {
+		if item.queueIndex != i {
+			return fmt.Errorf("%w: item %v at position %d has queue index %d", ErrIntegrity, item.key, i, item.queueIndex)
+		}
+		if current, exists := cache.items.Get(item.key); !exists || current != item {
+			return fmt.Errorf("%w: queued item %v is not in the map", ErrIntegrity, item.key)
+		}
+		if item.lock != &cache.mutex {
+			return fmt.Errorf("%w: item %v is not locked by the cache", ErrIntegrity, item.key)
+		}
+		if i > 0 && queue.Less(i, (i-1)/2) {
+			return fmt.Errorf("%w: item %v expires before its parent in the queue", ErrIntegrity, item.key)
+		}
+		if !cache.isShutDown && item.expiredAt(limit) {
+			return fmt.Errorf("%w: item %v expired at %s but was not removed", ErrIntegrity, item.key, item.ExpireAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
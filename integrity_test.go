@@ -0,0 +1,38 @@
+package ttlcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_VerifyIntegrity(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.SetWithTTL(Key(i), i, time.Duration(i%7)*time.Minute)
+	}
+	for i := 0; i < 100; i += 3 {
+		cache.Remove(Key(i))
+	}
+	assert.Nil(t, cache.VerifyIntegrity())
+
+	cache.mutex.Lock()
+	item := cache.priorityQueue.items[1]
+	item.queueIndex = 2
+	cache.mutex.Unlock()
+	assert.True(t, errors.Is(cache.VerifyIntegrity(), ErrIntegrity))
+	cache.mutex.Lock()
+	item.queueIndex = 1
+	cache.items.Delete(item.key)
+	cache.mutex.Unlock()
+	assert.True(t, errors.Is(cache.VerifyIntegrity(), ErrIntegrity), "Expected a queued item missing in the map to be found")
+	cache.mutex.Lock()
+	cache.items.Set(item.key, item)
+	item.ExpireAt = time.Now().Add(-time.Hour)
+	cache.mutex.Unlock()
+	assert.True(t, errors.Is(cache.VerifyIntegrity(), ErrIntegrity), "Expected an expired item to be found")
+}
@@ -0,0 +1,27 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// CacheInterface is the API of a Cache that decorators, such as a logging or read-only cache, and fakes in tests
+// implement. *Cache implements it.
+type CacheInterface = CacheInterfaceOf[string]
+
+// CacheInterfaceOf is the API of a CacheOf with keys of type K, *CacheOf[K] implements it
+type CacheInterfaceOf[K comparable] interface {
+	Set(key K, data interface{})
+	SetWithTTL(key K, data interface{}, ttl time.Duration)
+	Get(key K) (interface{}, bool)
+	GetTTL(key K) (time.Duration, bool)
+	Remove(key K) bool
+	Count() int
+	Purge()
+	Close()
+}
+
+var (
+	_ CacheInterface          = (*Cache)(nil)
+	_ CacheInterfaceOf[int64] = (*CacheOf[int64])(nil)
+	_ CacheInterface          = (*ShardedCache)(nil)
+)
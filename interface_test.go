@@ -0,0 +1,29 @@
+package ttlcache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingCache is a decorator counting the lookups of the cache it wraps
+type countingCache struct {
+	CacheInterface
+	lookups int
+}
+
+func (cache *countingCache) Get(key string) (interface{}, bool) {
+	cache.lookups++
+	return cache.CacheInterface.Get(key)
+}
+
+func TestCacheInterface_Decorator(t *testing.T) {
+	var cache CacheInterface = &countingCache{CacheInterface: NewCache()}
+	defer cache.Close()
+
+	cache.Set("key", "value")
+	data, exists := cache.Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	assert.Equal(t, 1, cache.(*countingCache).lookups)
+}
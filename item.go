@@ -1,6 +1,8 @@
 package ttlcache
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,36 +13,147 @@ const (
 	ItemExpireWithGlobalTTL time.Duration = 0
 )
 
-func newItem(key string, data interface{}, ttl time.Duration) *Item {
-	item := &Item{
-		Data: data,
-		TTL:  ttl,
-		key:  key,
+func newItem[K comparable](key K, data interface{}, ttl time.Duration, clock Clock) *ItemOf[K] {
+	item := &ItemOf[K]{
+		Data:      data,
+		TTL:       ttl,
+		key:       key,
+		createdAt: clock.Now(),
+		clock:     clock,
 	}
 	// since nobody is aware yet of this Item, it's safe to touch without lock here
 	item.touch()
 	return item
 }
 
-type Item struct {
-	key        string
+// Item is an entry of a Cache
+type Item = ItemOf[string]
+
+// ItemOf is an entry of a CacheOf with keys of type K. The cache changes Data, TTL and ExpireAt while holding its
+// lock, read them through Value and ExpiresAt, or use GetItemMeta, to not race with it.
+type ItemOf[K comparable] struct {
+	// fastHits counts the hits of the lock-free path of SetReadMostly, it comes first to be aligned for atomic access
+	fastHits   int64
+	key        K
 	Data       interface{}
 	TTL        time.Duration
 	ExpireAt   time.Time
 	queueIndex int
+	hits       int64
+	createdAt  time.Time
+	version    uint64
+	validator  string
+	seq        uint64
+	// weight is the cost reported with the value, for instance by a loader, or computed by the weigher of the cache
+	weight       int64
+	costReported bool
+	// writeExpireAt is the expiration time the item got when it was written
+	writeExpireAt time.Time
+	// deadline is the end of the maximum lifetime of the item, it is zero without one
+	deadline time.Time
+	// touchOnHit overrides SkipTtlExtensionOnHit for this item
+	touchOnHit touchMode
+	// globalTTL is set when the item was written without an individual TTL
+	globalTTL bool
+	// hotScore is the decayed hit count of SetHotKeyRefresh, hotSeen the hits it accounted for
+	hotScore float64
+	hotSeen  int64
+	// checkExpire replaces the check expiration callback of the cache for this item
+	checkExpire checkExpireCallback[K]
+	tags        []string
+	// lock is the mutex of the cache the item is stored in
+	lock *sync.Mutex
+	// clock is the clock of the cache the item is stored in
+	clock Clock
+}
+
+// ItemMeta is an ItemMetaOf an Item of a Cache
+type ItemMeta = ItemMetaOf[string]
+
+// ItemMetaOf is a copy of an item and its metadata, it is safe to use without locking
+type ItemMetaOf[K comparable] struct {
+	Key   K
+	Value interface{}
+	// TTL is the lifetime the item gets on every touch, ItemNotExpire for items that do not expire
+	TTL time.Duration
+	// ExpireAt is zero for items that do not expire
+	ExpireAt  time.Time
+	CreatedAt time.Time
+	// Hits is the number of Get calls that found the item
+	Hits int64
+	// Version changes on every write of the key, see CacheOf.SetIfVersion
+	Version uint64
+	// Validator is set with CacheOf.SetWithValidator
+	Validator string
+	// Cost is the cost reported by a ResultLoaderFunc or computed by the weigher, zero when unknown
+	Cost int64
+}
+
+// Key returns the key of the item
+func (item *ItemOf[K]) Key() K {
+	return item.key
+}
+
+// Value returns the value of the item, holding the lock of its cache
+func (item *ItemOf[K]) Value() interface{} {
+	if item.lock != nil {
+		item.lock.Lock()
+		defer item.lock.Unlock()
+	}
+	return item.Data
+}
+
+// ExpiresAt returns the expiration time of the item, holding the lock of its cache.
+// It is zero for items that do not expire.
+func (item *ItemOf[K]) ExpiresAt() time.Time {
+	if item.lock != nil {
+		item.lock.Lock()
+		defer item.lock.Unlock()
+	}
+	if !item.expires() {
+		return time.Time{}
+	}
+	return item.ExpireAt
+}
+
+// hitCount returns the hits of the item including those of the lock-free path, the cache mutex must be held
+func (item *ItemOf[K]) hitCount() int64 {
+	return item.hits + atomic.LoadInt64(&item.fastHits)
 }
 
 // Reset the Item expiration time
-func (item *Item) touch() {
+func (item *ItemOf[K]) touch() {
 	if item.TTL > 0 {
-		item.ExpireAt = time.Now().Add(item.TTL)
+		item.ExpireAt = item.clock.Now().Add(item.TTL)
+	}
+}
+
+// capLifetime keeps the expiration time within the maximum lifetime of the item, also for items without a TTL
+func (item *ItemOf[K]) capLifetime() {
+	if !item.deadline.IsZero() && (item.ExpireAt.IsZero() || item.ExpireAt.After(item.deadline)) {
+		item.ExpireAt = item.deadline
 	}
 }
 
+// expires reports whether the item has an expiration time, from its TTL or its maximum lifetime
+func (item *ItemOf[K]) expires() bool {
+	return item.TTL > 0 || !item.deadline.IsZero()
+}
+
 // Verify if the Item is expired
-func (item *Item) expired() bool {
-	if item.TTL <= 0 {
+func (item *ItemOf[K]) expired() bool {
+	return item.expiredAt(item.clock.Now())
+}
+
+// expiredAt reports whether the item is expired at the given time
+func (item *ItemOf[K]) expiredAt(now time.Time) bool {
+	if !item.expires() {
 		return false
 	}
-	return item.ExpireAt.Before(time.Now())
+	return item.ExpireAt.Before(now)
+}
+
+// outlivedAt reports whether the item reached the end of its maximum lifetime at the given time
+func (item *ItemOf[K]) outlivedAt(now time.Time) bool {
+	return !item.deadline.IsZero() && item.deadline.Before(now)
 }
@@ -8,14 +8,14 @@ import (
 )
 
 func TestItemExpired(t *testing.T) {
-	item := newItem("key", "value", (time.Duration(100) * time.Millisecond))
+	item := newItem("key", "value", (time.Duration(100) * time.Millisecond), realClock{})
 	assert.Equal(t, item.expired(), false, "Expected Item to not be expired")
 	<-time.After(200 * time.Millisecond)
 	assert.Equal(t, item.expired(), true, "Expected Item to be expired once time has passed")
 }
 
 func TestItemTouch(t *testing.T) {
-	item := newItem("key", "value", (time.Duration(100) * time.Millisecond))
+	item := newItem("key", "value", (time.Duration(100) * time.Millisecond), realClock{})
 	oldExpireAt := item.ExpireAt
 	<-time.After(50 * time.Millisecond)
 	item.touch()
@@ -28,7 +28,22 @@ func TestItemTouch(t *testing.T) {
 }
 
 func TestItemWithoutExpiration(t *testing.T) {
-	item := newItem("key", "value", ItemNotExpire)
+	item := newItem("key", "value", ItemNotExpire, realClock{})
 	<-time.After(50 * time.Millisecond)
 	assert.Equal(t, item.expired(), false, "Expected Item to not be expired")
 }
+
+func TestItemAccessors(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("key", "value", time.Minute)
+
+	cache.mutex.Lock()
+	item, _ := cache.items.Get("key")
+	cache.mutex.Unlock()
+	go cache.SetWithTTL("key", "changed", time.Hour)
+	assert.Equal(t, "key", item.Key())
+	assert.Contains(t, []interface{}{"value", "changed"}, item.Value())
+	assert.False(t, item.ExpiresAt().IsZero())
+	assert.True(t, newItem("key", "value", ItemNotExpire, realClock{}).ExpiresAt().IsZero())
+}
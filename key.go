@@ -0,0 +1,67 @@
+package ttlcache
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The parts of a composite key are separated by keySeparator, a zero byte inside a part is escaped as keyEscapedZero.
+// Both start with a zero byte, and the separator sorts before the escape and before every other byte,
+// so composite keys sort like their lists of parts.
+const (
+	keySeparator   = "\x00\x01"
+	keyEscapedZero = "\x00\xff"
+)
+
+var (
+	keyEscaper   = strings.NewReplacer("\x00", keyEscapedZero)
+	keyUnescaper = strings.NewReplacer(keyEscapedZero, "\x00")
+)
+
+// Key builds a composite key from parts formatted with fmt.Sprint. Different lists of parts never build the same key,
+// whatever characters the parts contain, and the keys sort like the lists of parts when compared as strings.
+func Key(parts ...interface{}) string {
+	var builder strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			builder.WriteString(keySeparator)
+		}
+		keyEscaper.WriteString(&builder, fmt.Sprint(part))
+	}
+	return builder.String()
+}
+
+// SplitKey returns the parts of a key built with Key
+func SplitKey(key string) []string {
+	parts := strings.Split(key, keySeparator)
+	for i, part := range parts {
+		parts[i] = keyUnescaper.Replace(part)
+	}
+	return parts
+}
+
+// HasKeyPrefix reports whether key was built with Key from a list of parts starting with the given parts
+func HasKeyPrefix(key string, parts ...interface{}) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	return hasKeyPrefix(key, Key(parts...))
+}
+
+func hasKeyPrefix(key string, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+keySeparator)
+}
+
+// RemoveKeyPrefix removes all items with a key built with Key from a list of parts starting with the given parts,
+// for instance all keys of a tenant. It returns the number of removed items.
+func (cache *Cache) RemoveKeyPrefix(parts ...interface{}) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if len(parts) == 0 {
+		return cache.removeFunc(func(key string, item *Item) bool { return true })
+	}
+	prefix := cache.normalize(Key(parts...))
+	return cache.removeFunc(func(key string, item *Item) bool {
+		return hasKeyPrefix(key, prefix)
+	})
+}
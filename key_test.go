@@ -0,0 +1,37 @@
+package ttlcache
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	assert.NotEqual(t, Key("a:b", "c"), Key("a", "b:c"))
+	assert.NotEqual(t, Key("a\x00", "b"), Key("a", "\x00b"))
+	assert.Equal(t, []string{"tenant", "42", "x\x00y"}, SplitKey(Key("tenant", 42, "x\x00y")))
+
+	keys := []string{Key("a", "b"), Key("a-b"), Key("a"), Key("a\x00"), Key("a", "a")}
+	sort.Strings(keys)
+	assert.Equal(t, []string{Key("a"), Key("a", "a"), Key("a", "b"), Key("a\x00"), Key("a-b")}, keys)
+
+	assert.True(t, HasKeyPrefix(Key("a", "b"), "a"))
+	assert.True(t, HasKeyPrefix(Key("a"), "a"))
+	assert.False(t, HasKeyPrefix(Key("ab"), "a"))
+}
+
+func TestCache_RemoveKeyPrefix(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.Set(Key("tenant1", "user", 1), 1)
+	cache.Set(Key("tenant1", "user", 2), 2)
+	cache.Set(Key("tenant1", "group", 1), 3)
+	cache.Set(Key("tenant10", "user", 1), 4)
+
+	assert.Equal(t, 2, cache.RemoveKeyPrefix("tenant1", "user"))
+	assert.Equal(t, 1, cache.RemoveKeyPrefix("tenant1"))
+	_, exists := cache.Get(Key("tenant10", "user", 1))
+	assert.True(t, exists, "Expected another tenant with a longer name to be kept")
+}
@@ -0,0 +1,54 @@
+package ttlcache
+
+import (
+	"sync"
+)
+
+// keyLock is the lock of one key, it is forgotten once nobody holds or waits for it
+type keyLock struct {
+	mutex sync.Mutex
+	refs  int
+}
+
+// LockKey locks the key, so callers can serialize an expensive computation of the value of a key without locking the
+// whole cache. It does not stop other callers from reading or writing the key, only other calls to LockKey wait.
+// Every LockKey must be followed by an UnlockKey of the same key.
+func (cache *CacheOf[K]) LockKey(key K) {
+	key = cache.normalizeKey(key)
+	cache.keyLocksMutex.Lock()
+	if cache.keyLocks == nil {
+		cache.keyLocks = make(map[K]*keyLock)
+	}
+	lock, exists := cache.keyLocks[key]
+	if !exists {
+		lock = &keyLock{}
+		cache.keyLocks[key] = lock
+	}
+	lock.refs++
+	cache.keyLocksMutex.Unlock()
+	lock.mutex.Lock()
+}
+
+// UnlockKey unlocks a key locked with LockKey, it panics when the key is not locked
+func (cache *CacheOf[K]) UnlockKey(key K) {
+	key = cache.normalizeKey(key)
+	cache.keyLocksMutex.Lock()
+	lock, exists := cache.keyLocks[key]
+	if !exists {
+		cache.keyLocksMutex.Unlock()
+		panic("ttlcache: UnlockKey of a key which is not locked")
+	}
+	lock.refs--
+	if lock.refs == 0 {
+		delete(cache.keyLocks, key)
+	}
+	cache.keyLocksMutex.Unlock()
+	lock.mutex.Unlock()
+}
+
+// normalizeKey applies the key normalizer without the cache mutex held
+func (cache *CacheOf[K]) normalizeKey(key K) K {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.normalize(key)
+}
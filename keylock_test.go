@@ -0,0 +1,37 @@
+package ttlcache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_LockKey(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	computations := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.LockKey("expensive")
+			defer cache.UnlockKey("expensive")
+			if _, exists := cache.Get("expensive"); !exists {
+				computations++
+				cache.Set("expensive", 42)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, computations)
+
+	cache.LockKey("a")
+	cache.LockKey("b")
+	cache.UnlockKey("a")
+	cache.UnlockKey("b")
+	assert.Len(t, cache.keyLocks, 0, "Expected unused locks to be forgotten")
+	assert.Panics(t, func() { cache.UnlockKey("a") })
+}
@@ -0,0 +1,331 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var (
+	// ErrNoLoader is returned by GetOrLoad and GetManyWithLoader when no loader is set
+	ErrNoLoader = errors.New("ttlcache: no loader set")
+	// ErrTooManyLoads is returned by loads which exceed the limit of SetMaxConcurrentLoads and do not wait
+	ErrTooManyLoads = errors.New("ttlcache: too many concurrent loads")
+	// ErrLoading is returned by GetOrLoad for a key which is being loaded, with the LoadingFail policy
+	ErrLoading = errors.New("ttlcache: key is being loaded")
+	// ErrLoaderPanicked is returned to the callers waiting for a load whose loader or second level panicked, the
+	// panic itself goes up the stack of the caller which ran it
+	ErrLoaderPanicked = errors.New("ttlcache: loader panicked")
+)
+
+// LoadingPolicy tells what GetOrLoad does when the key is being loaded by another call
+type LoadingPolicy int
+
+const (
+	// LoadingWait waits for the running load and returns its result
+	LoadingWait LoadingPolicy = iota
+	// LoadingFail returns ErrLoading right away, for callers which rather serve a fallback than wait
+	LoadingFail
+)
+
+// loadCall is the placeholder of a running load, the result is set before done is closed
+type loadCall struct {
+	done   chan struct{}
+	result LoadResult
+	err    error
+}
+
+// LoaderFunc loads the value of a missing key and the TTL to store it with. The context carries the deadline of
+// the attempt, see RetryPolicy.
+type LoaderFunc[K comparable] func(ctx context.Context, key K) (interface{}, time.Duration, error)
+
+// LoadResult is the outcome of a ResultLoaderFunc. The data source may determine the TTL, for instance from the
+// Cache-Control header of an HTTP response, and the cost of the value, which counts against cost limits instead of
+// the CostFunc of a tenant.
+type LoadResult struct {
+	Value interface{}
+	TTL   time.Duration
+	// Cost is zero when unknown
+	Cost int64
+}
+
+// ResultLoaderFunc loads the value of a missing key with its TTL and cost
+type ResultLoaderFunc[K comparable] func(ctx context.Context, key K) (LoadResult, error)
+
+// RetryPolicy tells how failed loads are retried. The zero policy tries once.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, each further wait is Multiplier times longer up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Multiplier defaults to 2
+	Multiplier float64
+	// Jitter is the fraction between 0 and 1 by which waits are randomly shortened, so callers do not retry in lockstep
+	Jitter float64
+	// AttemptTimeout is the deadline of every attempt, zero leaves only the deadline of the caller
+	AttemptTimeout time.Duration
+	// Retryable reports whether an error is worth another attempt, by default all errors are
+	Retryable func(err error) bool
+}
+
+// backoff returns the wait before the given retry, counting from 1
+func (policy RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	wait := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if policy.MaxBackoff > 0 && wait > float64(policy.MaxBackoff) {
+		wait = float64(policy.MaxBackoff)
+	}
+	if policy.Jitter > 0 {
+		wait -= rand.Float64() * policy.Jitter * wait
+	}
+	return time.Duration(wait)
+}
+
+// SetLoader sets the loader GetOrLoad calls for missing keys
+func (cache *CacheOf[K]) SetLoader(loader LoaderFunc[K]) {
+	cache.SetResultLoader(func(ctx context.Context, key K) (LoadResult, error) {
+		data, ttl, err := loader(ctx, key)
+		return LoadResult{Value: data, TTL: ttl}, err
+	})
+}
+
+// SetResultLoader sets a loader which also reports the cost of the values, it replaces the loader of SetLoader
+func (cache *CacheOf[K]) SetResultLoader(loader ResultLoaderFunc[K]) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.loader = loader
+}
+
+// SetLoaderRetryPolicy sets how failed loads are retried
+func (cache *CacheOf[K]) SetLoaderRetryPolicy(policy RetryPolicy) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.retryPolicy = policy
+}
+
+// SetLoadingPolicy sets what GetOrLoad does for a key another call is loading, the default is LoadingWait
+func (cache *CacheOf[K]) SetLoadingPolicy(policy LoadingPolicy) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.loadingPolicy = policy
+}
+
+// SetMaxConcurrentLoads limits the number of loader calls running at the same time across the cache, which protects
+// the data source when an empty cache is hit after a deploy. Further loads wait for a free slot when wait is true,
+// otherwise they fail with ErrTooManyLoads. Zero removes the limit. Loads already waiting keep the previous limit.
+func (cache *CacheOf[K]) SetMaxConcurrentLoads(limit int, wait bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.loadSlots = nil
+	if limit > 0 {
+		cache.loadSlots = make(chan struct{}, limit)
+	}
+	cache.waitForLoadSlot = wait
+}
+
+// SetReadThrough makes Get load missing keys like GetOrLoad, with the loader set by SetLoader or SetResultLoader.
+// Concurrent misses of a key share a single load. Get misses when the load fails.
+func (cache *CacheOf[K]) SetReadThrough(enabled bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.readThrough = enabled
+}
+
+// GetOrLoad returns the value of the key, or loads, stores and returns it when the key is missing. A second level set
+// with SetSecondLevel is tried first, the loader is called when it misses or fails. Concurrent calls for the same
+// missing key wait for a single load and share its result, see SetLoadingPolicy. Failed attempts are retried
+// according to the RetryPolicy until ctx is done, the error of the last attempt is returned. Loaded values bypass the
+// middleware, see Use.
+func (cache *CacheOf[K]) GetOrLoad(ctx context.Context, key K) (interface{}, error) {
+	if data, exists := cache.lookup(key); exists {
+		return data, nil
+	}
+	return cache.loadMissing(ctx, key)
+}
+
+// loadMissing is GetOrLoad after the lookup missed, so the miss is counted once
+func (cache *CacheOf[K]) loadMissing(ctx context.Context, key K) (interface{}, error) {
+	cache.mutex.Lock()
+	loader, policy, level, closed := cache.loader, cache.retryPolicy, cache.secondLevel, cache.isShutDown
+	cache.mutex.Unlock()
+	if closed {
+		return nil, ErrCacheClosed
+	}
+	if level != nil {
+		data, ttl, found, err := cache.fetch(ctx, level, key)
+		if found && err == nil {
+			cache.setWithOptions(key, data, ttl, itemOptions[K]{})
+			return cache.copyValue(data), nil
+		}
+		if loader == nil {
+			if err == nil {
+				err = ErrNotFound
+			}
+			return nil, err
+		}
+	}
+	if loader == nil {
+		return nil, ErrNoLoader
+	}
+
+	return cache.loadOnce(ctx, key, loader, policy)
+}
+
+// GetOrCompute returns the value of the key, or calls f and stores and returns the value with the TTL f returns when
+// the key is missing. Concurrent calls for the same missing key, including those of GetOrLoad, wait for a single call
+// and share its result, so f runs at most once per miss. Errors are returned to all waiting callers and not stored.
+// The LoadingPolicy and the limit of SetMaxConcurrentLoads apply like for GetOrLoad.
+func (cache *CacheOf[K]) GetOrCompute(key K, f func() (interface{}, time.Duration, error)) (interface{}, error) {
+	if data, exists := cache.lookup(key); exists {
+		return data, nil
+	}
+	if cache.IsClosed() {
+		return nil, ErrCacheClosed
+	}
+	compute := func(ctx context.Context, key K) (LoadResult, error) {
+		data, ttl, err := f()
+		return LoadResult{Value: data, TTL: ttl}, err
+	}
+	return cache.loadOnce(context.Background(), key, compute, RetryPolicy{})
+}
+
+// loadOnce loads the key, unless a load of the key is running already, in which case it waits for its result or fails
+// with ErrLoading, depending on the LoadingPolicy
+func (cache *CacheOf[K]) loadOnce(ctx context.Context, key K, loader ResultLoaderFunc[K], policy RetryPolicy) (interface{}, error) {
+	normalized := cache.normalizeKey(key)
+	cache.mutex.Lock()
+	if call, running := cache.loads[normalized]; running {
+		if cache.loadingPolicy == LoadingFail {
+			cache.mutex.Unlock()
+			return nil, ErrLoading
+		}
+		cache.mutex.Unlock()
+		select {
+		case <-call.done:
+			return cache.copyLoaded(call)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	// the error stays when the loader panics
+	call := &loadCall{done: make(chan struct{}), err: ErrLoaderPanicked}
+	if cache.loads == nil {
+		cache.loads = make(map[K]*loadCall)
+	}
+	cache.loads[normalized] = call
+	cache.mutex.Unlock()
+	defer func() {
+		cache.mutex.Lock()
+		delete(cache.loads, normalized)
+		cache.mutex.Unlock()
+		close(call.done)
+	}()
+
+	// a load may have finished since the caller missed, the miss is counted already
+	if data, _, exists := cache.peek(key); exists {
+		call.result, call.err = LoadResult{Value: data}, nil
+	} else if call.result, call.err = cache.load(ctx, key, loader, policy); call.err == nil {
+		cache.storeLoaded(key, call.result)
+	} else if data, exists := cache.stale(key); exists {
+		call.result, call.err = LoadResult{Value: data}, nil
+	}
+	return cache.copyLoaded(call)
+}
+
+// copyLoaded returns the result of a finished load, every caller gets its own copy of the value like from Get, since
+// the value is the one the cache stored
+func (cache *CacheOf[K]) copyLoaded(call *loadCall) (interface{}, error) {
+	if call.err != nil {
+		return nil, call.err
+	}
+	return cache.copyValue(call.result.Value), nil
+}
+
+// copyValue copies a value with the value copier, when one is set
+func (cache *CacheOf[K]) copyValue(data interface{}) interface{} {
+	cache.mutex.Lock()
+	copier := cache.valueCopier
+	cache.mutex.Unlock()
+	if copier == nil {
+		return data
+	}
+	return copier(data)
+}
+
+// storeLoaded stores the result of a load with its cost, a zero cost leaves the weight to the weigher
+func (cache *CacheOf[K]) storeLoaded(key K, result LoadResult) {
+	cache.setWithOptions(key, result.Value, result.TTL, itemOptions[K]{cost: result.Cost})
+}
+
+// load calls the loader until an attempt succeeds, the policy gives up, ctx is done or the cache is closed
+func (cache *CacheOf[K]) load(ctx context.Context, key K, loader ResultLoaderFunc[K], policy RetryPolicy) (LoadResult, error) {
+	cache.mutex.Lock()
+	slots, wait := cache.loadSlots, cache.waitForLoadSlot
+	cache.mutex.Unlock()
+	for attempt := 1; ; attempt++ {
+		if err := cache.acquireLoadSlot(ctx, slots, wait); err != nil {
+			return LoadResult{}, err
+		}
+		result, err := loadAttempt(ctx, key, loader, policy, slots)
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || (policy.Retryable != nil && !policy.Retryable(err)) {
+			return LoadResult{}, err
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return LoadResult{}, err
+		case <-cache.done:
+			timer.Stop()
+			return LoadResult{}, err
+		}
+	}
+}
+
+// loadAttempt calls the loader once and releases the load slot, also when the loader panics
+func loadAttempt[K comparable](ctx context.Context, key K, loader ResultLoaderFunc[K], policy RetryPolicy,
+	slots chan struct{}) (LoadResult, error) {
+	if slots != nil {
+		defer func() { <-slots }()
+	}
+	if policy.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.AttemptTimeout)
+		defer cancel()
+	}
+	return loader(ctx, key)
+}
+
+// acquireLoadSlot takes one of the slots of SetMaxConcurrentLoads, nil slots mean there is no limit
+func (cache *CacheOf[K]) acquireLoadSlot(ctx context.Context, slots chan struct{}, wait bool) error {
+	if slots == nil {
+		return nil
+	}
+	if !wait {
+		select {
+		case slots <- struct{}{}:
+			return nil
+		default:
+			return ErrTooManyLoads
+		}
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cache.done:
+		return ErrCacheClosed
+	}
+}
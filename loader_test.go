@@ -0,0 +1,361 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetOrLoad(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	_, err := cache.GetOrLoad(context.Background(), "key")
+	assert.Equal(t, ErrNoLoader, err)
+
+	var loads int32
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(10 * time.Millisecond)
+		return "loaded " + key, time.Minute, nil
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := cache.GetOrLoad(context.Background(), "key")
+			assert.Nil(t, err)
+			assert.Equal(t, "loaded key", data)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads), "Expected concurrent misses to load once")
+	ttl, _ := cache.GetTTL("key")
+	assert.Equal(t, time.Minute, ttl)
+}
+
+func TestCache_SetReadThrough(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var loads int32
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(10 * time.Millisecond)
+		if key == "missing" {
+			return nil, 0, ErrNotFound
+		}
+		return "loaded " + key, time.Minute, nil
+	})
+	_, exists := cache.Get("key")
+	assert.False(t, exists, "Expected Get not to load before SetReadThrough")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&loads))
+
+	cache.SetReadThrough(true)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, exists := cache.Get("key")
+			assert.True(t, exists)
+			assert.Equal(t, "loaded key", data)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads), "Expected concurrent misses to load once")
+
+	_, exists = cache.Get("missing")
+	assert.False(t, exists)
+	assert.Equal(t, 1, cache.Count())
+}
+
+func TestCache_SetReadThroughMetrics(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return "loaded", time.Minute, nil
+	})
+	cache.SetReadThrough(true)
+	data, exists := cache.Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "loaded", data)
+	metrics := cache.GetMetrics()
+	assert.Equal(t, int64(1), metrics.Retrievals, "Expected a read-through miss to count as one retrieval")
+	assert.Equal(t, int64(1), metrics.Misses)
+}
+
+func TestCache_SetReadThroughCopiesValues(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetValueCopier(func(value interface{}) interface{} {
+		return append([]int(nil), value.([]int)...)
+	}, false)
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return []int{1, 2, 3}, time.Minute, nil
+	})
+	cache.SetReadThrough(true)
+	data, exists := cache.Get("key")
+	assert.True(t, exists)
+	data.([]int)[0] = 42
+
+	data, _ = cache.Peek("key")
+	assert.Equal(t, []int{1, 2, 3}, data, "Expected the loaded value to be copied for the caller")
+	data, err := cache.GetOrCompute("other", func() (interface{}, time.Duration, error) {
+		return []int{4}, time.Minute, nil
+	})
+	assert.Nil(t, err)
+	data.([]int)[0] = 42
+	data, _ = cache.Peek("other")
+	assert.Equal(t, []int{4}, data, "Expected the computed value to be copied for the caller")
+}
+
+func TestCache_GetOrCompute(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var calls, started int32
+	failing := errors.New("backend down")
+	entered := make(chan struct{})
+	compute := func() (interface{}, time.Duration, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(entered)
+			// the first call only returns once the others had time to wait for its result
+			for atomic.LoadInt32(&started) < 9 {
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil, 0, failing
+		}
+		return "computed", time.Minute, nil
+	}
+	var wg sync.WaitGroup
+	getOrCompute := func() {
+		defer wg.Done()
+		_, err := cache.GetOrCompute("key", compute)
+		assert.Equal(t, failing, err)
+	}
+	wg.Add(1)
+	go getOrCompute()
+	<-entered
+	for i := 0; i < 9; i++ {
+		wg.Add(1)
+		go func() {
+			atomic.AddInt32(&started, 1)
+			getOrCompute()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "Expected concurrent misses to share the failed call")
+	assert.False(t, cache.Has("key"), "Expected errors not to be stored")
+
+	data, err := cache.GetOrCompute("key", compute)
+	assert.Nil(t, err)
+	assert.Equal(t, "computed", data)
+	data, err = cache.GetOrCompute("key", compute)
+	assert.Nil(t, err)
+	assert.Equal(t, "computed", data)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	ttl, _ := cache.GetTTL("key")
+	assert.Equal(t, time.Minute, ttl)
+}
+
+func TestCache_GetOrLoadRetry(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	failure := errors.New("unavailable")
+	var attempts int32
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "Expected a deadline per attempt")
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return nil, 0, failure
+		}
+		return "value", 0, nil
+	})
+	cache.SetLoaderRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Jitter: 0.5, AttemptTimeout: time.Second})
+	data, err := cache.GetOrLoad(context.Background(), "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", data)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	atomic.StoreInt32(&attempts, -10)
+	_, err = cache.GetOrLoad(context.Background(), "other")
+	assert.Equal(t, failure, err, "Expected the error of the last attempt")
+	assert.Equal(t, int32(-7), atomic.LoadInt32(&attempts))
+
+	cache.SetLoaderRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = cache.GetOrLoad(ctx, "third")
+	assert.Equal(t, failure, err, "Expected the wait to stop with the context")
+
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+}
+
+func TestCache_SetMaxConcurrentLoads(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	var running, maxRunning int32
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		if current := atomic.AddInt32(&running, 1); current > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, current)
+		}
+		defer atomic.AddInt32(&running, -1)
+		started <- struct{}{}
+		<-release
+		return key, 0, nil
+	})
+
+	cache.SetMaxConcurrentLoads(1, false)
+	done := make(chan struct{})
+	go func() {
+		cache.GetOrLoad(context.Background(), "a")
+		done <- struct{}{}
+	}()
+	<-started
+	_, err := cache.GetOrLoad(context.Background(), "b")
+	assert.Equal(t, ErrTooManyLoads, err)
+	release <- struct{}{}
+	<-done
+
+	cache.SetMaxConcurrentLoads(2, true)
+	for _, key := range []string{"c", "d", "e"} {
+		go func(key string) {
+			_, err := cache.GetOrLoad(context.Background(), key)
+			assert.Nil(t, err)
+			done <- struct{}{}
+		}(key)
+	}
+	<-started
+	<-started
+	close(release)
+	for i := 0; i < 3; i++ {
+		<-done
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+	assert.Equal(t, 4, cache.Count())
+}
+
+func TestCache_SetResultLoader(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	cache.SetResultLoader(func(ctx context.Context, key string) (LoadResult, error) {
+		return LoadResult{Value: []byte("body"), TTL: 30 * time.Second, Cost: 4}, nil
+	})
+	tenant := cache.Tenant("a")
+	data, err := cache.GetOrLoad(context.Background(), tenant.key("page"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("body"), data)
+
+	meta, _ := cache.GetItemMeta(tenant.key("page"))
+	assert.Equal(t, int64(4), meta.Cost)
+	assert.Equal(t, 30*time.Second, meta.TTL)
+	assert.Equal(t, int64(4), tenant.Stats().Cost, "Expected the reported cost to count for the tenant")
+}
+
+func TestCache_GetOrLoadStoresWithoutMiddleware(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	sets := 0
+	cache.Use(Middleware{
+		Set: func(next SetFunc[string]) SetFunc[string] {
+			return func(key string, data interface{}, ttl time.Duration) {
+				sets++
+				next(key, data, ttl)
+			}
+		},
+	})
+	cache.SetResultLoader(func(ctx context.Context, key string) (LoadResult, error) {
+		if key == "costly" {
+			return LoadResult{Value: key, Cost: 4}, nil
+		}
+		return LoadResult{Value: key}, nil
+	})
+	for _, key := range []string{"free", "costly"} {
+		data, err := cache.GetOrLoad(context.Background(), key)
+		assert.Nil(t, err)
+		assert.Equal(t, key, data)
+	}
+	assert.Equal(t, 0, sets, "Expected loads with and without a cost to be stored the same way")
+	assert.Equal(t, 2, cache.Count())
+}
+
+func TestCache_SetLoadingPolicy(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	failure := errors.New("unavailable")
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var loads int32
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		close(started)
+		<-release
+		return nil, 0, failure
+	})
+
+	results := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := cache.GetOrLoad(context.Background(), "key")
+			results <- err
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	cache.SetLoadingPolicy(LoadingFail)
+	_, err := cache.GetOrLoad(context.Background(), "key")
+	assert.Equal(t, ErrLoading, err)
+
+	close(release)
+	assert.Equal(t, failure, <-results)
+	assert.Equal(t, failure, <-results, "Expected the waiter to share the result of the load")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
+
+func TestCache_GetOrLoadPanic(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetMaxConcurrentLoads(1, false)
+	panicking := true
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		if panicking {
+			panic("broken loader")
+		}
+		return "value", 0, nil
+	})
+
+	func() {
+		defer func() {
+			assert.Equal(t, "broken loader", recover(), "Expected the panic to reach the caller")
+		}()
+		_, _ = cache.GetOrLoad(context.Background(), "key")
+	}()
+
+	panicking = false
+	cache.SetLoadingPolicy(LoadingFail)
+	data, err := cache.GetOrLoad(context.Background(), "key")
+	assert.Nil(t, err, "Expected the panicked load and its slot to be released")
+	assert.Equal(t, "value", data)
+}
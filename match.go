@@ -0,0 +1,156 @@
+package ttlcache
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrBadPattern is returned for a malformed glob pattern
+var ErrBadPattern = errors.New("ttlcache: syntax error in pattern")
+
+// KeysMatching returns the sorted keys of the items which are not expired and match the glob pattern.
+// A '*' matches any sequence of characters, separators included, a '?' matches one character, '[abc]', '[a-z]'
+// and '[!abc]' or '[^abc]' match one character of a class, and '\' escapes the next character, also in a class.
+// It visits every item with the cache locked, so it takes time linear in the size of the cache;
+// keep it for admin tooling and invalidation rather than the request path.
+func (cache *Cache) KeysMatching(pattern string) ([]string, error) {
+	expression, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return cache.KeysMatchingRegexp(expression), nil
+}
+
+// KeysMatchingRegexp returns the sorted keys of the items which are not expired and match the regular expression,
+// it has the same cost as KeysMatching
+func (cache *Cache) KeysMatchingRegexp(expression *regexp.Regexp) []string {
+	cache.mutex.Lock()
+	var keys []string
+	cache.items.Range(func(key string, item *Item) bool {
+		if !item.expired() && expression.MatchString(key) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	cache.mutex.Unlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// RemoveMatching removes the items whose key matches the regular expression and returns how many there were. The
+// removal callback is called for each of them. It has the same cost as KeysMatching.
+func (cache *Cache) RemoveMatching(expression *regexp.Regexp) int {
+	cache.mutex.Lock()
+	removed := cache.removeFunc(func(key string, item *Item) bool {
+		return expression.MatchString(key)
+	})
+	cache.mutex.Unlock()
+	if removed > 0 {
+		cache.notifyExpiration()
+	}
+	return removed
+}
+
+// KeysWithPrefix returns the sorted keys of the items which are not expired and start with the prefix, such as
+// "user:123:". It has the same cost as KeysMatching.
+func (cache *Cache) KeysWithPrefix(prefix string) []string {
+	cache.mutex.Lock()
+	prefix = cache.normalize(prefix)
+	var keys []string
+	cache.items.Range(func(key string, item *Item) bool {
+		if !item.expired() && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	cache.mutex.Unlock()
+	sort.Strings(keys)
+	return keys
+}
+
+// RemoveByPrefix removes the items whose key starts with the prefix and returns how many there were. The removal
+// callback is called for each of them.
+func (cache *Cache) RemoveByPrefix(prefix string) int {
+	cache.mutex.Lock()
+	prefix = cache.normalize(prefix)
+	removed := cache.removeFunc(func(key string, item *Item) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+	cache.mutex.Unlock()
+	if removed > 0 {
+		cache.notifyExpiration()
+	}
+	return removed
+}
+
+// globToRegexp translates a glob pattern of KeysMatching into an anchored regular expression
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("(?s)^")
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		case '[':
+			end, err := writeGlobClass(&builder, pattern, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case '\\':
+			i++
+			if i == len(pattern) {
+				return nil, ErrBadPattern
+			}
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	builder.WriteString("$")
+	expression, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, ErrBadPattern
+	}
+	return expression, nil
+}
+
+// writeGlobClass translates the character class starting at pattern[start] and returns the index of its closing ']'.
+// The characters of the class are quoted, so only ranges and the negation keep their meaning.
+func writeGlobClass(builder *strings.Builder, pattern string, start int) (int, error) {
+	i := start + 1
+	builder.WriteString("[")
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		builder.WriteString("^")
+		i++
+	}
+	empty := true
+	for ; i < len(pattern) && pattern[i] != ']'; i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+			if i == len(pattern) {
+				return 0, ErrBadPattern
+			}
+			if pattern[i] == '-' {
+				builder.WriteString(`\-`)
+			} else {
+				builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		case '-':
+			builder.WriteString("-")
+		default:
+			builder.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+		empty = false
+	}
+	if i == len(pattern) || empty {
+		return 0, ErrBadPattern
+	}
+	builder.WriteString("]")
+	return i, nil
+}
@@ -0,0 +1,78 @@
+package ttlcache
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_KeysMatching(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	for _, key := range []string{"user:1", "user:2", "user:10/profile", "group:1", "user*"} {
+		cache.Set(key, true)
+	}
+
+	keys, err := cache.KeysMatching("user:*")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"user:1", "user:10/profile", "user:2"}, keys)
+	keys, _ = cache.KeysMatching("user:?")
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+	keys, _ = cache.KeysMatching("[!u]*:[0-9]")
+	assert.Equal(t, []string{"group:1"}, keys)
+	keys, _ = cache.KeysMatching(`user\*`)
+	assert.Equal(t, []string{"user*"}, keys)
+	_, err = cache.KeysMatching("user:[")
+	assert.Equal(t, ErrBadPattern, err)
+	_, err = cache.KeysMatching("user:[]")
+	assert.Equal(t, ErrBadPattern, err)
+	keys, _ = cache.KeysMatching("user:[^2]")
+	assert.Equal(t, []string{"user:1"}, keys)
+	keys, _ = cache.KeysMatching(`user[\*]`)
+	assert.Equal(t, []string{"user*"}, keys)
+	keys, _ = cache.KeysMatching(`user:[1-3]`)
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+	keys, _ = cache.KeysMatching(`user:[1\-3]`)
+	assert.Equal(t, []string{"user:1"}, keys, "Expected an escaped hyphen not to form a range")
+
+	assert.Equal(t, []string{"user:10/profile"}, cache.KeysMatchingRegexp(regexp.MustCompile(`/profile$`)))
+}
+
+func TestCache_KeysWithPrefix(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("user:123:name", "a")
+	cache.Set("user:123:email", "b")
+	cache.Set("user:1234:name", "c")
+	cache.Set("session:123", "d")
+
+	assert.Equal(t, []string{"user:123:email", "user:123:name"}, cache.KeysWithPrefix("user:123:"))
+	assert.Len(t, cache.KeysWithPrefix(""), 4)
+	assert.Empty(t, cache.KeysWithPrefix("group:"))
+
+	assert.Equal(t, 2, cache.RemoveByPrefix("user:123:"))
+	assert.Equal(t, 0, cache.RemoveByPrefix("user:123:"))
+	assert.Equal(t, []string{"user:1234:name"}, cache.KeysWithPrefix("user:"))
+	assert.Equal(t, 2, cache.Count())
+}
+
+func TestCache_RemoveMatching(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	removed := make(chan string, 10)
+	cache.SetRemovalCallback(func(key string, value interface{}, reason EventType) {
+		if reason == EventRemoved {
+			removed <- key
+		}
+	})
+	for _, key := range []string{"user:1", "user:2", "user:10/profile", "group:1"} {
+		cache.Set(key, true)
+	}
+
+	assert.Equal(t, 2, cache.RemoveMatching(regexp.MustCompile(`^user:\d$`)))
+	assert.Equal(t, 0, cache.RemoveMatching(regexp.MustCompile(`^session:`)))
+	assert.Equal(t, []string{"group:1", "user:10/profile"}, cache.KeysMatchingRegexp(regexp.MustCompile(`.`)))
+	cache.Drain()
+	assert.Len(t, removed, 2)
+}
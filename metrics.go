@@ -0,0 +1,93 @@
+package ttlcache
+
+import (
+	"sync/atomic"
+)
+
+// Metrics contains common cache metrics so you can calculate hit and miss rates
+type Metrics struct {
+	// Inserted is the number of items that were added to the cache
+	Inserted int64
+	// Retrievals is the number of Get calls
+	Retrievals int64
+	// Hits is the number of Get calls that found an Item
+	Hits int64
+	// Misses is the number of Get calls that did not find an Item
+	Misses int64
+	// Evicted is the number of items that were removed because they expired or did not fit in the size limit
+	Evicted int64
+	// AsyncDropped is the number of SetAsync writes that were dropped because the queue was full
+	AsyncDropped int64
+	// Cost is the total cost of the items, see SetWeigher
+	Cost int64
+	// QueueLength is the number of items in the expiration queue
+	QueueLength int
+	// QueueMaxLength is the largest number of items the expiration queue held
+	QueueMaxLength int
+	// QueuePushes, QueuePops, QueueRemovals and QueueFixes count the insertions into the expiration queue, the items
+	// taken out of it because they expired or were evicted, the items removed explicitly, for instance by Remove, and
+	// the reorderings of the queue
+	QueuePushes   int64
+	QueuePops     int64
+	QueueRemovals int64
+	QueueFixes    int64
+	// QueuePushRate, QueuePopRate, QueueRemovalRate and QueueFixRate are the same operations per second, they show
+	// how much work expiration bookkeeping causes. They are averaged over the time between the last two samples, a
+	// sample is taken by GetMetrics when a second or more passed since the previous one.
+	QueuePushRate    float64
+	QueuePopRate     float64
+	QueueRemovalRate float64
+	QueueFixRate     float64
+}
+
+// countedMetrics returns the counters including the hits of the lock-free path, the cache mutex must be held
+func (cache *CacheOf[K]) countedMetrics() Metrics {
+	metrics := cache.metrics
+	fastHits := atomic.LoadInt64(&cache.fastHits)
+	metrics.Hits += fastHits
+	metrics.Retrievals += fastHits
+	return metrics
+}
+
+// GetMetrics exposes the metrics of the cache. This is a snapshot copy of the metrics.
+func (cache *CacheOf[K]) GetMetrics() Metrics {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	metrics := cache.countedMetrics()
+	metrics.Cost = cache.totalCost
+	stats := cache.priorityQueue.statistics(cache.clock.Now())
+	metrics.QueueLength = cache.priorityQueue.Len()
+	metrics.QueueMaxLength = stats.maxLength
+	metrics.QueuePushes = stats.pushes
+	metrics.QueuePops = stats.pops
+	metrics.QueueRemovals = stats.removals
+	metrics.QueueFixes = stats.fixes
+	metrics.QueuePushRate = stats.rates.pushes
+	metrics.QueuePopRate = stats.rates.pops
+	metrics.QueueRemovalRate = stats.rates.removals
+	metrics.QueueFixRate = stats.rates.fixes
+	return metrics
+}
+
+// add adds the metrics of another cache, QueueMaxLength becomes the largest of both
+func (metrics *Metrics) add(other Metrics) {
+	metrics.Inserted += other.Inserted
+	metrics.Retrievals += other.Retrievals
+	metrics.Hits += other.Hits
+	metrics.Misses += other.Misses
+	metrics.Evicted += other.Evicted
+	metrics.AsyncDropped += other.AsyncDropped
+	metrics.Cost += other.Cost
+	metrics.QueueLength += other.QueueLength
+	if other.QueueMaxLength > metrics.QueueMaxLength {
+		metrics.QueueMaxLength = other.QueueMaxLength
+	}
+	metrics.QueuePushes += other.QueuePushes
+	metrics.QueuePops += other.QueuePops
+	metrics.QueueRemovals += other.QueueRemovals
+	metrics.QueueFixes += other.QueueFixes
+	metrics.QueuePushRate += other.QueuePushRate
+	metrics.QueuePopRate += other.QueuePopRate
+	metrics.QueueRemovalRate += other.QueueRemovalRate
+	metrics.QueueFixRate += other.QueueFixRate
+}
@@ -0,0 +1,48 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// SetFunc stores an item, it is the signature of SetWithTTL
+type SetFunc[K comparable] func(key K, data interface{}, ttl time.Duration)
+
+// GetFunc looks up an item, it is the signature of Get
+type GetFunc[K comparable] func(key K) (interface{}, bool)
+
+// Middleware wraps the Set and Get operations of a Cache
+type Middleware = MiddlewareOf[string]
+
+// MiddlewareOf wraps the Set and Get operations of a CacheOf. Each function receives the next step of the chain and
+// returns the step to call instead, which may change the arguments and results or not call next at all.
+// A nil function leaves the operation alone.
+type MiddlewareOf[K comparable] struct {
+	Set func(next SetFunc[K]) SetFunc[K]
+	Get func(next GetFunc[K]) GetFunc[K]
+}
+
+// Use adds a middleware to the Set, SetWithTTL and Get calls of the cache, and to SetMany and GetMany, which are built
+// on them. The middleware added first is the outermost: it sees the arguments first and the results last.
+//
+// No other operation passes through the middleware, as a SetFunc cannot carry their options or conditions: writes
+// with options, such as SetWithOptions, SetWithTags and SetWithValidator, the conditional and read-modify-write
+// operations, such as SetIfVersion, CompareAndSwap, Replace, Swap, Update, GetOrSet and Increment, the writes of a
+// Txn or a Tenant and the values GetOrLoad and the refreshes store, as well as lookups such as Peek and Pop. They still
+// copy values with the copier of SetValueCopier.
+func (cache *CacheOf[K]) Use(middleware MiddlewareOf[K]) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.middleware = append(cache.middleware, middleware)
+	cache.invalidateReads()
+
+	set, get := cache.setWithTTL, cache.get
+	for i := len(cache.middleware) - 1; i >= 0; i-- {
+		if cache.middleware[i].Set != nil {
+			set = cache.middleware[i].Set(set)
+		}
+		if cache.middleware[i].Get != nil {
+			get = cache.middleware[i].Get(get)
+		}
+	}
+	cache.setChain, cache.getChain = set, get
+}
@@ -0,0 +1,49 @@
+package ttlcache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Use(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var calls []string
+	cache.Use(Middleware{
+		Set: func(next SetFunc[string]) SetFunc[string] {
+			return func(key string, data interface{}, ttl time.Duration) {
+				calls = append(calls, "outer set")
+				next(key, data, ttl)
+			}
+		},
+	})
+	cache.Use(Middleware{
+		Set: func(next SetFunc[string]) SetFunc[string] {
+			return func(key string, data interface{}, ttl time.Duration) {
+				calls = append(calls, "inner set")
+				next(key, strings.ToUpper(data.(string)), ttl)
+			}
+		},
+		Get: func(next GetFunc[string]) GetFunc[string] {
+			return func(key string) (interface{}, bool) {
+				data, exists := next(key)
+				if exists {
+					data = strings.ToLower(data.(string))
+				}
+				return data, exists
+			}
+		},
+	})
+
+	cache.Set("key", "Value")
+	assert.Equal(t, []string{"outer set", "inner set"}, calls)
+	data, _, _ := cache.peek("key")
+	assert.Equal(t, "VALUE", data, "Expected Set middleware to change the stored value")
+	data, exists := cache.Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data, "Expected Get middleware to change the returned value")
+}
@@ -0,0 +1,120 @@
+package ttlcache
+
+import (
+	"strings"
+	"time"
+)
+
+// NamespaceSeparator separates the name of a Namespace from the keys in it
+const NamespaceSeparator = ":"
+
+// namespaceEscaper escapes the separator and zero bytes in the name of a namespace, like keyEscaper, so the first
+// separator of a key always ends the name
+var namespaceEscaper = strings.NewReplacer("\x00", keyEscapedZero, NamespaceSeparator, keySeparator)
+
+// Namespace is a view on a part of a Cache: its keys are stored in the Cache prefixed with the name of the namespace
+// and the separator. All namespaces share the map and the expiration goroutine of their Cache, so many logical caches
+// cost no more than one. Count and Purge visit all items of the Cache.
+type Namespace struct {
+	cache  *Cache
+	name   string
+	prefix string
+	ttl    time.Duration
+}
+
+// Namespace returns the view on the keys prefixed with name and NamespaceSeparator,
+// every call with the same name returns the same Namespace. A NamespaceSeparator in the name is escaped in the prefix,
+// so the keys of one namespace are never part of another.
+func (cache *Cache) Namespace(name string) *Namespace {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if namespace, exists := cache.namespaces[name]; exists {
+		return namespace
+	}
+	if cache.namespaces == nil {
+		cache.namespaces = make(map[string]*Namespace)
+	}
+	namespace := &Namespace{cache: cache, name: name, prefix: namespaceEscaper.Replace(name) + NamespaceSeparator}
+	cache.namespaces[name] = namespace
+	return namespace
+}
+
+// Name returns the name of the namespace
+func (namespace *Namespace) Name() string {
+	return namespace.name
+}
+
+// SetTTL sets the default TTL of the namespace, used by Set. When it is zero Set uses the global TTL of the Cache.
+func (namespace *Namespace) SetTTL(ttl time.Duration) {
+	namespace.cache.mutex.Lock()
+	namespace.ttl = ttl
+	namespace.cache.mutex.Unlock()
+}
+
+// Set adds the item with the default TTL of the namespace
+func (namespace *Namespace) Set(key string, data interface{}) {
+	namespace.cache.mutex.Lock()
+	ttl := namespace.ttl
+	namespace.cache.mutex.Unlock()
+	namespace.SetWithTTL(key, data, ttl)
+}
+
+// SetWithTTL adds the item with an individual TTL
+func (namespace *Namespace) SetWithTTL(key string, data interface{}, ttl time.Duration) {
+	namespace.cache.SetWithTTL(namespace.prefix+key, data, ttl)
+}
+
+// Get looks up an item of the namespace
+func (namespace *Namespace) Get(key string) (interface{}, bool) {
+	return namespace.cache.Get(namespace.prefix + key)
+}
+
+// Has reports whether an item of the namespace exists and is not expired, see CacheOf.Has
+func (namespace *Namespace) Has(key string) bool {
+	return namespace.cache.Has(namespace.prefix + key)
+}
+
+// GetTTL returns the TTL of an item of the namespace
+func (namespace *Namespace) GetTTL(key string) (time.Duration, bool) {
+	return namespace.cache.GetTTL(namespace.prefix + key)
+}
+
+// Keys returns the sorted keys of the items of the namespace which are not expired, without the prefix
+func (namespace *Namespace) Keys() []string {
+	keys := namespace.cache.KeysWithPrefix(namespace.prefix)
+	prefix := namespace.cache.normalizeKey(namespace.prefix)
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, prefix)
+	}
+	return keys
+}
+
+// Remove removes an item of the namespace
+func (namespace *Namespace) Remove(key string) bool {
+	return namespace.cache.Remove(namespace.prefix + key)
+}
+
+// Count returns the number of items in the namespace which are not expired, like Keys
+func (namespace *Namespace) Count() int {
+	namespace.cache.mutex.Lock()
+	defer namespace.cache.mutex.Unlock()
+	prefix := namespace.cache.normalize(namespace.prefix)
+	count := 0
+	namespace.cache.items.Range(func(key string, item *Item) bool {
+		if strings.HasPrefix(key, prefix) && !item.expired() {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// Purge removes all items of the namespace, the rest of the Cache is untouched
+func (namespace *Namespace) Purge() {
+	namespace.cache.mutex.Lock()
+	defer namespace.cache.mutex.Unlock()
+	prefix := namespace.cache.normalize(namespace.prefix)
+	namespace.cache.removeFunc(func(key string, item *Item) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
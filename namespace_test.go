@@ -0,0 +1,81 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Namespace(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	users := cache.Namespace("users")
+	assert.Equal(t, users, cache.Namespace("users"))
+	assert.Equal(t, "users", users.Name())
+	sessions := cache.Namespace("sessions")
+	sessions.SetTTL(50 * time.Millisecond)
+
+	users.Set("1", "alice")
+	sessions.Set("1", "token")
+	cache.Set("plain", "value")
+
+	data, exists := users.Get("1")
+	assert.True(t, exists)
+	assert.Equal(t, "alice", data)
+	data, _ = cache.Get("sessions:1")
+	assert.Equal(t, "token", data, "Expected namespaces to share the cache")
+	assert.Equal(t, 1, users.Count())
+	assert.Equal(t, 3, cache.Count())
+
+	<-time.After(100 * time.Millisecond)
+	_, exists = sessions.Get("1")
+	assert.False(t, exists, "Expected the namespace TTL to apply")
+	_, exists = users.Get("1")
+	assert.True(t, exists)
+
+	users.Set("2", "bob")
+	assert.Equal(t, []string{"1", "2"}, users.Keys())
+	assert.True(t, users.Has("2"))
+	assert.False(t, sessions.Has("2"))
+	ttl, exists := users.GetTTL("2")
+	assert.True(t, exists)
+	assert.Equal(t, time.Duration(0), ttl)
+	users.Purge()
+	assert.Equal(t, 0, users.Count())
+	assert.Equal(t, 1, cache.Count(), "Expected purge to leave other keys alone")
+	assert.False(t, users.Remove("1"))
+}
+
+func TestCache_NamespaceSeparatorInName(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	nested, outer := cache.Namespace("a:b"), cache.Namespace("a")
+	assert.Equal(t, "a:b", nested.Name())
+	nested.Set("c", "nested")
+	outer.Set("b:c", "outer")
+	data, _ := nested.Get("c")
+	assert.Equal(t, "nested", data, "Expected the separator in the name to be escaped")
+	assert.Equal(t, []string{"c"}, nested.Keys())
+	assert.Equal(t, []string{"b:c"}, outer.Keys())
+	nested.Purge()
+	assert.True(t, outer.Has("b:c"))
+}
+
+func TestCache_NamespaceCountSkipsExpired(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	users := cache.Namespace("users")
+	users.Set("1", "alice")
+	users.Set("2", "bob")
+	cache.mutex.Lock()
+	item, _ := cache.items.Get("users:2")
+	item.TTL, item.ExpireAt = time.Minute, time.Now().Add(-time.Second)
+	cache.mutex.Unlock()
+
+	assert.Equal(t, []string{"1"}, users.Keys())
+	assert.Equal(t, 1, users.Count(), "Expected Count to skip expired items like Keys")
+}
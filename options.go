@@ -0,0 +1,104 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// touchMode is whether hits extend the TTL of an item
+type touchMode int
+
+const (
+	// touchDefault follows SkipTtlExtensionOnHit
+	touchDefault touchMode = iota
+	touchAlways
+	touchNever
+)
+
+// itemSettings are the settings ItemOptions make
+type itemSettings struct {
+	ttl         time.Duration
+	expireAt    time.Time
+	touchOnHit  touchMode
+	maxLifetime time.Duration
+}
+
+// ItemOption configures a single write of SetWithOptions
+type ItemOption func(settings *itemSettings)
+
+// WithTTL stores the item with an individual TTL, like SetWithTTL. Without it the global TTL applies.
+func WithTTL(ttl time.Duration) ItemOption {
+	return func(settings *itemSettings) {
+		settings.ttl = ttl
+	}
+}
+
+// WithExpireAt stores the item until the given point in time, like SetWithExpireAt. Hits do not extend such items,
+// unless WithTouchOnHit is given as well.
+func WithExpireAt(at time.Time) ItemOption {
+	return func(settings *itemSettings) {
+		settings.expireAt = at
+	}
+}
+
+// WithTouchOnHit makes hits extend the TTL of the item, a sliding expiration, even with SkipTtlExtensionOnHit
+func WithTouchOnHit() ItemOption {
+	return func(settings *itemSettings) {
+		settings.touchOnHit = touchAlways
+	}
+}
+
+// WithNoTouchOnHit keeps hits from extending the TTL of the item, a fixed expiration, as SkipTtlExtensionOnHit does for
+// all items
+func WithNoTouchOnHit() ItemOption {
+	return func(settings *itemSettings) {
+		settings.touchOnHit = touchNever
+	}
+}
+
+// WithIdleTimeout stores the item until it was not read for the timeout, it is WithTTL and WithTouchOnHit combined
+func WithIdleTimeout(timeout time.Duration) ItemOption {
+	return func(settings *itemSettings) {
+		settings.ttl = timeout
+		settings.touchOnHit = touchAlways
+	}
+}
+
+// WithMaxLifetime limits how long the item lives after it was first stored, whatever its TTL or expiration time, see
+// SetMaxLifetime
+func WithMaxLifetime(lifetime time.Duration) ItemOption {
+	return func(settings *itemSettings) {
+		settings.maxLifetime = lifetime
+	}
+}
+
+// SetWithOptions stores the item configured by the options, so sliding and fixed expirations can be mixed in one
+// cache. Without options it is the same as Set, except that it bypasses the middleware, see Use.
+//
+//	cache.SetWithOptions("session", session, WithTTL(30*time.Minute), WithTouchOnHit())
+//	cache.SetWithOptions("quote", quote, WithTTL(time.Minute), WithNoTouchOnHit())
+//	cache.SetWithOptions("login", login, WithIdleTimeout(15*time.Minute), WithMaxLifetime(8*time.Hour))
+func (cache *CacheOf[K]) SetWithOptions(key K, data interface{}, options ...ItemOption) {
+	var settings itemSettings
+	for _, option := range options {
+		option(&settings)
+	}
+	ttl := settings.ttl
+	if !settings.expireAt.IsZero() {
+		cache.mutex.Lock()
+		ttl = settings.expireAt.Sub(cache.clock.Now())
+		if ttl <= 0 {
+			removed := cache.remove(key)
+			cache.mutex.Unlock()
+			if removed {
+				cache.notifyExpiration()
+			}
+			return
+		}
+		cache.mutex.Unlock()
+		if settings.touchOnHit == touchDefault {
+			settings.touchOnHit = touchNever
+		}
+	}
+	cache.setWithOptions(key, data, ttl, itemOptions[K]{expireAt: settings.expireAt, touchOnHit: settings.touchOnHit,
+		maxLifetime: settings.maxLifetime})
+}
@@ -0,0 +1,110 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetWithOptions(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetTTL(time.Hour)
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetReadMostly(true)
+	cache.SetWithOptions("session", "user", WithTTL(time.Minute), WithTouchOnHit())
+	cache.SetWithOptions("deadline", "job", WithTTL(time.Minute))
+	cache.SetWithOptions("default", "value")
+
+	session, _ := cache.GetExpireAt("session")
+	deadline, _ := cache.GetExpireAt("deadline")
+	ttl, _ := cache.GetTTL("default")
+	assert.Equal(t, time.Hour, ttl, "Expected the global TTL without options")
+	for i := 0; i < 5; i++ {
+		// builds the read snapshot, which must not hide the sliding item
+		_, _ = cache.Get("deadline")
+	}
+	time.Sleep(10 * time.Millisecond)
+	_, _ = cache.Get("session")
+
+	extended, _ := cache.GetExpireAt("session")
+	assert.True(t, extended.After(session), "Expected a hit to extend a sliding item")
+	unchanged, _ := cache.GetExpireAt("deadline")
+	assert.True(t, unchanged.Equal(deadline), "Expected SkipTtlExtensionOnHit to apply to other items")
+}
+
+func TestCache_SetWithOptionsNoTouchOnHit(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetReadMostly(true)
+	cache.SetWithOptions("fixed", "value", WithTTL(time.Minute), WithNoTouchOnHit())
+	cache.SetWithTTL("sliding", "value", time.Minute)
+
+	fixed, _ := cache.GetExpireAt("fixed")
+	sliding, _ := cache.GetExpireAt("sliding")
+	time.Sleep(10 * time.Millisecond)
+	_, _ = cache.Get("fixed")
+	_, _ = cache.Get("sliding")
+
+	unchanged, _ := cache.GetExpireAt("fixed")
+	assert.True(t, unchanged.Equal(fixed), "Expected a hit not to extend a fixed item")
+	extended, _ := cache.GetExpireAt("sliding")
+	assert.True(t, extended.After(sliding), "Expected hits to extend other items")
+}
+
+func TestCache_SetWithOptionsMaxLifetime(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithOptions("login", "user", WithIdleTimeout(time.Hour), WithMaxLifetime(90*time.Minute))
+	deadline := time.Now().Add(90 * time.Minute)
+
+	assert.True(t, cache.ExtendTTL("login", time.Hour))
+	expireAt, _ := cache.GetExpireAt("login")
+	assert.False(t, expireAt.After(deadline), "Expected the lifetime to limit the extension")
+	assert.True(t, expireAt.After(deadline.Add(-time.Second)))
+	_, _ = cache.Get("login")
+	expireAt, _ = cache.GetExpireAt("login")
+	assert.True(t, expireAt.Before(time.Now().Add(time.Hour+time.Second)), "Expected a hit to restart the idle timeout")
+
+	cache.SetMaxLifetime(time.Minute)
+	cache.SetWithTTL("global", "value", time.Hour)
+	remaining, _ := cache.GetRemainingTTL("global")
+	assert.True(t, remaining <= time.Minute, "Expected the lifetime of the cache to apply, got %v", remaining)
+	cache.SetWithOptions("own", "value", WithIdleTimeout(time.Hour), WithMaxLifetime(2*time.Minute))
+	remaining, _ = cache.GetRemainingTTL("own")
+	assert.True(t, remaining > time.Minute && remaining <= 2*time.Minute, "Expected the lifetime of the item to apply")
+}
+
+func TestCache_MaxLifetimeWithoutTTL(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithOptions("forever", "value", WithTTL(ItemNotExpire), WithMaxLifetime(time.Hour))
+	cache.SetWithOptions("deadline", "value", WithExpireAt(time.Now().Add(2*time.Hour)), WithMaxLifetime(time.Hour))
+
+	for _, key := range []string{"forever", "deadline"} {
+		expireAt, exists := cache.GetExpireAt(key)
+		assert.True(t, exists)
+		assert.False(t, expireAt.IsZero(), "Expected %s to expire at the end of its lifetime", key)
+		assert.False(t, expireAt.After(time.Now().Add(time.Hour)), "Expected the lifetime to cap %s", key)
+	}
+	cache.ProcessExpirations(time.Now().Add(90 * time.Minute))
+	assert.False(t, cache.Has("forever"), "Expected an item without TTL to reach its maximum lifetime")
+	assert.False(t, cache.Has("deadline"))
+}
+
+func TestCache_MaxLifetimeRewrite(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetMaxLifetime(time.Hour)
+	cache.SetWithTTL("key", 1, 50*time.Minute)
+	deadline := time.Now().Add(time.Hour)
+	time.Sleep(10 * time.Millisecond)
+
+	cache.SetWithTTL("key", 2, 50*time.Minute)
+	cache.SetWithTTL("key", 3, ItemNotExpire)
+	expireAt, _ := cache.GetExpireAt("key")
+	assert.False(t, expireAt.After(deadline), "Expected a rewrite not to restart the lifetime")
+	cache.ProcessExpirations(deadline.Add(time.Second))
+	assert.False(t, cache.Has("key"))
+}
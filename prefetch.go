@@ -0,0 +1,86 @@
+package ttlcache
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultPrefetchConcurrency is the number of concurrent prefetch loads when SetPrefetchConcurrency was not called
+const defaultPrefetchConcurrency = 4
+
+// SetPrefetchConcurrency sets how many loads of Prefetch run at the same time
+func (cache *CacheOf[K]) SetPrefetchConcurrency(concurrency int) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.prefetchConcurrency = concurrency
+	cache.prefetchSlots = nil
+}
+
+// SetPrefetchCallback sets a callback that is called when a load scheduled by Prefetch completes, err is nil when the
+// value was stored
+func (cache *CacheOf[K]) SetPrefetchCallback(callback func(key K, err error)) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.prefetchCallback = callback
+}
+
+// Prefetch loads the keys which are not cached in the background, like GetOrLoad, so a request handler can warm the
+// keys it expects to need next without waiting. Close cancels the loads that are still running.
+func (cache *CacheOf[K]) Prefetch(keys ...K) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.isShutDown {
+		return
+	}
+	var missing []K
+	for _, key := range keys {
+		if item, exists := cache.items.Get(cache.normalize(key)); !exists || item.expired() {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	if cache.prefetchSlots == nil {
+		concurrency := cache.prefetchConcurrency
+		if concurrency <= 0 {
+			concurrency = defaultPrefetchConcurrency
+		}
+		cache.prefetchSlots = make(chan struct{}, concurrency)
+	}
+	cache.workers.Add(1)
+	go cache.prefetch(missing, cache.prefetchSlots, cache.prefetchCallback)
+}
+
+// prefetch starts a load for every key as soon as one of the slots is free
+func (cache *CacheOf[K]) prefetch(keys []K, slots chan struct{}, callback func(key K, err error)) {
+	defer cache.workers.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-cache.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var loads sync.WaitGroup
+	defer loads.Wait()
+	for _, key := range keys {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		loads.Add(1)
+		go func(key K) {
+			defer loads.Done()
+			_, err := cache.GetOrLoad(ctx, key)
+			<-slots
+			if callback != nil {
+				callback(key, err)
+			}
+		}(key)
+	}
+}
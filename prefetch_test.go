@@ -0,0 +1,67 @@
+package ttlcache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Prefetch(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var running, maxRunning int32
+	started, release := make(chan string, 10), make(chan struct{})
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+		started <- key
+		<-release
+		return "loaded " + key, 0, nil
+	})
+	completed := make(chan string, 10)
+	cache.SetPrefetchCallback(func(key string, err error) {
+		assert.Nil(t, err)
+		completed <- key
+	})
+	cache.SetPrefetchConcurrency(2)
+	cache.Set("cached", "value")
+
+	cache.Prefetch("a", "b", "c", "d", "e", "cached")
+	// both slots are taken before any load may finish
+	<-started
+	<-started
+	assert.Equal(t, int32(2), atomic.LoadInt32(&running))
+	for i := 0; i < 5; i++ {
+		release <- struct{}{}
+		<-completed
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+	assert.Len(t, started, 3)
+	data, _ := cache.Get("e")
+	assert.Equal(t, "loaded e", data)
+	data, _ = cache.Get("cached")
+	assert.Equal(t, "value", data, "Expected cached keys to not be loaded")
+}
+
+func TestCache_PrefetchCanceledByClose(t *testing.T) {
+	cache := NewCache()
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		<-ctx.Done()
+		return nil, 0, ctx.Err()
+	})
+	cache.SetPrefetchConcurrency(1)
+	cache.Prefetch("a", "b")
+	time.Sleep(10 * time.Millisecond)
+	cache.Close()
+	assert.Equal(t, 0, cache.Count())
+}
@@ -2,44 +2,145 @@ package ttlcache
 
 import (
 	"container/heap"
+	"time"
 )
 
-func newPriorityQueue() *priorityQueue {
-	queue := &priorityQueue{}
+func newPriorityQueue[K comparable]() *priorityQueue[K] {
+	queue := &priorityQueue[K]{}
 	heap.Init(queue)
 	return queue
 }
 
-type priorityQueue struct {
-	items []*Item
+type priorityQueue[K comparable] struct {
+	items []*ItemOf[K]
+	stats queueStats
+	// batching defers restoring the heap order to endBatch
+	batching bool
 }
 
-func (pq *priorityQueue) update(item *Item) {
+// queueStats counts the operations on a priority queue
+type queueStats struct {
+	queueCounts
+	maxLength int
+	// sampled are the counts at sampledAt, the start of the period the rates are measured over
+	sampled   queueCounts
+	sampledAt time.Time
+	rates     queueRates
+}
+
+// queueCounts are the numbers of operations on a priority queue
+type queueCounts struct {
+	pushes   int64
+	pops     int64
+	removals int64
+	fixes    int64
+}
+
+// queueRates are the operations on a priority queue per second
+type queueRates struct {
+	pushes   float64
+	pops     float64
+	removals float64
+	fixes    float64
+}
+
+// The methods used by the cache do nothing on a nil queue, which a cache without expiration has.
+
+func (pq *priorityQueue[K]) update(item *ItemOf[K]) {
+	if pq == nil {
+		return
+	}
+	if pq.batching {
+		return
+	}
+	pq.stats.fixes++
 	heap.Fix(pq, item.queueIndex)
 }
 
-func (pq *priorityQueue) push(item *Item) {
-	heap.Push(pq, item)
+func (pq *priorityQueue[K]) push(item *ItemOf[K]) {
+	if pq == nil {
+		return
+	}
+	pq.stats.pushes++
+	if pq.batching {
+		pq.Push(item)
+	} else {
+		heap.Push(pq, item)
+	}
+	if pq.Len() > pq.stats.maxLength {
+		pq.stats.maxLength = pq.Len()
+	}
 }
 
-func (pq *priorityQueue) pop() *Item {
+func (pq *priorityQueue[K]) pop() *ItemOf[K] {
 	if pq.Len() == 0 {
 		return nil
 	}
-	return heap.Pop(pq).(*Item)
+	pq.stats.pops++
+	return heap.Pop(pq).(*ItemOf[K])
 }
 
-func (pq *priorityQueue) remove(item *Item) {
+// remove takes the item out of the queue, popped counts it as a pop instead of a removal, for items which leave the
+// cache because they expired or were evicted
+func (pq *priorityQueue[K]) remove(item *ItemOf[K], popped bool) {
+	if pq == nil {
+		return
+	}
+	if popped {
+		pq.stats.pops++
+	} else {
+		pq.stats.removals++
+	}
 	heap.Remove(pq, item.queueIndex)
 }
 
-func (pq priorityQueue) Len() int {
-	length := len(pq.items)
-	return length
+// startBatch makes push and update skip restoring the heap order until endBatch, which restores it once for all
+// changes. The order of the items is undefined in between.
+func (pq *priorityQueue[K]) startBatch() {
+	if pq == nil {
+		return
+	}
+	pq.batching = true
+}
+
+func (pq *priorityQueue[K]) endBatch() {
+	if pq == nil {
+		return
+	}
+	pq.batching = false
+	heap.Init(pq)
+}
+
+func (pq *priorityQueue[K]) Len() int {
+	if pq == nil {
+		return 0
+	}
+	return len(pq.items)
+}
+
+// statistics returns the operation counts, zero for a nil queue. The rates are averaged over the time between the
+// last two samples, a sample is taken when a second or more passed since the previous one.
+func (pq *priorityQueue[K]) statistics(now time.Time) queueStats {
+	if pq == nil {
+		return queueStats{}
+	}
+	if pq.stats.sampledAt.IsZero() {
+		pq.stats.sampled, pq.stats.sampledAt = pq.stats.queueCounts, now
+	} else if elapsed := now.Sub(pq.stats.sampledAt).Seconds(); elapsed >= 1 {
+		counts, sampled := pq.stats.queueCounts, pq.stats.sampled
+		pq.stats.rates = queueRates{
+			pushes:   float64(counts.pushes-sampled.pushes) / elapsed,
+			pops:     float64(counts.pops-sampled.pops) / elapsed,
+			removals: float64(counts.removals-sampled.removals) / elapsed,
+			fixes:    float64(counts.fixes-sampled.fixes) / elapsed,
+		}
+		pq.stats.sampled, pq.stats.sampledAt = counts, now
+	}
+	return pq.stats
 }
 
 // Less will consider items with time.Time default value (epoch start) as more than set items.
-func (pq priorityQueue) Less(i, j int) bool {
+func (pq priorityQueue[K]) Less(i, j int) bool {
 	if pq.items[i].ExpireAt.IsZero() {
 		return false
 	}
@@ -49,19 +150,19 @@ func (pq priorityQueue) Less(i, j int) bool {
 	return pq.items[i].ExpireAt.Before(pq.items[j].ExpireAt)
 }
 
-func (pq priorityQueue) Swap(i, j int) {
+func (pq priorityQueue[K]) Swap(i, j int) {
 	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
 	pq.items[i].queueIndex = i
 	pq.items[j].queueIndex = j
 }
 
-func (pq *priorityQueue) Push(x interface{}) {
-	item := x.(*Item)
+func (pq *priorityQueue[K]) Push(x interface{}) {
+	item := x.(*ItemOf[K])
 	item.queueIndex = len(pq.items)
 	pq.items = append(pq.items, item)
 }
 
-func (pq *priorityQueue) Pop() interface{} {
+func (pq *priorityQueue[K]) Pop() interface{} {
 	old := pq.items
 	n := len(old)
 	item := old[n-1]
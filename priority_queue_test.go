@@ -9,26 +9,26 @@ import (
 )
 
 func TestPriorityQueuePush(t *testing.T) {
-	queue := newPriorityQueue()
+	queue := newPriorityQueue[string]()
 	for i := 0; i < 10; i++ {
-		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", -1))
+		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", -1, realClock{}))
 	}
 	assert.Equal(t, queue.Len(), 10, "Expected queue to have 10 elements")
 }
 
 func TestPriorityQueuePop(t *testing.T) {
-	queue := newPriorityQueue()
+	queue := newPriorityQueue[string]()
 	for i := 0; i < 10; i++ {
-		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", -1))
+		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", -1, realClock{}))
 	}
 	for i := 0; i < 5; i++ {
 		item := queue.pop()
-		assert.Equal(t, fmt.Sprintf("%T", item), "*ttlcache.Item", "Expected 'Item' to be a '*ttlcache.Item'")
+		assert.Equal(t, fmt.Sprintf("%T", item), "*ttlcache.ItemOf[string]", "Expected 'Item' to be a '*ttlcache.ItemOf[string]'")
 	}
 	assert.Equal(t, queue.Len(), 5, "Expected queue to have 5 elements")
 	for i := 0; i < 5; i++ {
 		item := queue.pop()
-		assert.Equal(t, fmt.Sprintf("%T", item), "*ttlcache.Item", "Expected 'Item' to be a '*ttlcache.Item'")
+		assert.Equal(t, fmt.Sprintf("%T", item), "*ttlcache.ItemOf[string]", "Expected 'Item' to be a '*ttlcache.ItemOf[string]'")
 	}
 	assert.Equal(t, queue.Len(), 0, "Expected queue to have 0 elements")
 
@@ -37,9 +37,9 @@ func TestPriorityQueuePop(t *testing.T) {
 }
 
 func TestPriorityQueueCheckOrder(t *testing.T) {
-	queue := newPriorityQueue()
+	queue := newPriorityQueue[string]()
 	for i := 10; i > 0; i-- {
-		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", time.Duration(i)*time.Second))
+		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", time.Duration(i)*time.Second, realClock{}))
 	}
 	for i := 1; i <= 10; i++ {
 		item := queue.pop()
@@ -48,12 +48,12 @@ func TestPriorityQueueCheckOrder(t *testing.T) {
 }
 
 func TestPriorityQueueRemove(t *testing.T) {
-	queue := newPriorityQueue()
+	queue := newPriorityQueue[string]()
 	items := make(map[string]*Item)
 	var itemRemove *Item
 	for i := 0; i < 5; i++ {
 		key := fmt.Sprintf("key_%d", i)
-		items[key] = newItem(key, "Data", time.Duration(i)*time.Second)
+		items[key] = newItem(key, "Data", time.Duration(i)*time.Second, realClock{})
 		queue.push(items[key])
 
 		if i == 2 {
@@ -61,7 +61,7 @@ func TestPriorityQueueRemove(t *testing.T) {
 		}
 	}
 	assert.Equal(t, queue.Len(), 5, "Expected queue to have 5 elements")
-	queue.remove(itemRemove)
+	queue.remove(itemRemove, false)
 	assert.Equal(t, queue.Len(), 4, "Expected queue to have 4 elements")
 
 	for {
@@ -76,8 +76,8 @@ func TestPriorityQueueRemove(t *testing.T) {
 }
 
 func TestPriorityQueueUpdate(t *testing.T) {
-	queue := newPriorityQueue()
-	item := newItem("key", "Data", 1*time.Second)
+	queue := newPriorityQueue[string]()
+	item := newItem("key", "Data", 1*time.Second, realClock{})
 	queue.push(item)
 	assert.Equal(t, queue.Len(), 1, "The queue is supose to be with 1 Item")
 
@@ -87,3 +87,23 @@ func TestPriorityQueueUpdate(t *testing.T) {
 	assert.Equal(t, newItem.key, "newKey", "The Item key didn't change")
 	assert.Equal(t, queue.Len(), 0, "The queue is supose to be with 0 items")
 }
+
+func TestPriorityQueueRates(t *testing.T) {
+	queue := newPriorityQueue[string]()
+	start := time.Now()
+	assert.Equal(t, queueRates{}, queue.statistics(start).rates, "Expected no rates before the first period")
+
+	for i := 0; i < 10; i++ {
+		queue.push(newItem(fmt.Sprintf("key_%d", i), "Data", time.Duration(i+1)*time.Second, realClock{}))
+	}
+	queue.pop()
+	queue.remove(queue.items[0], false)
+	queue.remove(queue.items[0], false)
+	assert.Equal(t, queueRates{}, queue.statistics(start.Add(time.Second/2)).rates,
+		"Expected the rates to be measured over at least a second")
+
+	stats := queue.statistics(start.Add(2 * time.Second))
+	assert.Equal(t, queueRates{pushes: 5, pops: 0.5, removals: 1}, stats.rates)
+	assert.Equal(t, int64(2), stats.removals)
+	assert.Equal(t, queueRates{}, queue.statistics(start.Add(4*time.Second)).rates, "Expected the next period to start")
+}
@@ -0,0 +1,97 @@
+package ttlcache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// readSnapshot is an immutable copy of the items that Get reads without locking in read-mostly mode
+type readSnapshot[K comparable] struct {
+	entries    map[K]readEntry
+	normalizer func(key K) K
+	copier     func(value interface{}) interface{}
+	clock      Clock
+}
+
+type readEntry struct {
+	data interface{}
+	// hits is the fastHits counter of the item
+	hits *int64
+	// expireAt is zero for items that do not expire
+	expireAt time.Time
+}
+
+// SetReadMostly enables a lock-free path for Get. Lookups read an immutable copy of the items, which every write
+// discards and which is rebuilt after as many locked lookups as there are items, like the read map of sync.Map.
+// This suits caches which are read far more often than written. The fast path is only taken while hits do not extend
+// the TTL, see SkipTtlExtensionOnHit and WithTouchOnHit, and without middleware or SetRefreshAhead. Hits on the fast
+// path count in the metrics, in the Hits of GetItemMeta and for SetHotKeyRefresh like any other hit.
+func (cache *CacheOf[K]) SetReadMostly(enabled bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.readMostly = enabled
+	cache.invalidateReads()
+}
+
+// getFast looks the key up in the read snapshot, ok is false when the locked path has to be taken
+func (cache *CacheOf[K]) getFast(key K) (data interface{}, ok bool) {
+	snapshot, _ := cache.readOnly.Load().(*readSnapshot[K])
+	if snapshot == nil {
+		return nil, false
+	}
+	if snapshot.normalizer != nil {
+		key = snapshot.normalizer(key)
+	}
+	entry, exists := snapshot.entries[key]
+	if !exists || (!entry.expireAt.IsZero() && entry.expireAt.Before(snapshot.clock.Now())) {
+		return nil, false
+	}
+	atomic.AddInt64(&cache.fastHits, 1)
+	atomic.AddInt64(entry.hits, 1)
+	if snapshot.copier != nil {
+		return snapshot.copier(entry.data), true
+	}
+	return entry.data, true
+}
+
+// invalidateReads discards the read snapshot after a change, the cache mutex must be held
+func (cache *CacheOf[K]) invalidateReads() {
+	cache.readMisses = 0
+	if snapshot, _ := cache.readOnly.Load().(*readSnapshot[K]); snapshot != nil {
+		cache.readOnly.Store((*readSnapshot[K])(nil))
+	}
+}
+
+// countReadMiss counts a locked lookup and builds the read snapshot once there were enough of them,
+// the cache mutex must be held
+func (cache *CacheOf[K]) countReadMiss() {
+	if !cache.readMostly || !cache.skipTTLExtension || cache.getChain != nil || cache.refreshAhead > 0 {
+		return
+	}
+	if snapshot, _ := cache.readOnly.Load().(*readSnapshot[K]); snapshot != nil {
+		return
+	}
+	cache.readMisses++
+	if cache.readMisses < cache.items.Len() {
+		return
+	}
+	snapshot := &readSnapshot[K]{
+		entries:    make(map[K]readEntry, cache.items.Len()),
+		normalizer: cache.keyNormalizer,
+		copier:     cache.valueCopier,
+		clock:      cache.clock,
+	}
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		if item.touchOnHit == touchAlways {
+			// hits on it must take the locked path to extend its TTL
+			return true
+		}
+		entry := readEntry{data: item.Data, hits: &item.fastHits}
+		if item.expires() {
+			entry.expireAt = item.ExpireAt
+		}
+		snapshot.entries[key] = entry
+		return true
+	})
+	cache.readOnly.Store(snapshot)
+}
@@ -0,0 +1,111 @@
+package ttlcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetReadMostly(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetReadMostly(true)
+
+	cache.SetWithTTL("a", 1, time.Minute)
+	cache.Set("b", 2)
+	for i := 0; i < 2; i++ {
+		cache.Get("a")
+	}
+	assert.NotNil(t, cache.readOnly.Load(), "Expected the snapshot after as many lookups as items")
+
+	data, exists := cache.Get("a")
+	assert.True(t, exists)
+	assert.Equal(t, 1, data)
+	_, exists = cache.Get("c")
+	assert.False(t, exists, "Expected a miss to take the locked path")
+
+	cache.Set("a", 3)
+	data, exists = cache.Get("a")
+	assert.True(t, exists)
+	assert.Equal(t, 3, data, "Expected a write to discard the snapshot")
+
+	cache.Remove("b")
+	_, exists = cache.Get("b")
+	assert.False(t, exists)
+
+	metrics := cache.GetMetrics()
+	assert.Equal(t, int64(6), metrics.Retrievals)
+	assert.Equal(t, int64(4), metrics.Hits)
+	assert.Equal(t, int64(2), metrics.Misses)
+}
+
+func TestCache_SetReadMostlyCountsItemHits(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetReadMostly(true)
+
+	cache.SetWithTTL("hot", 1, time.Minute)
+	cache.SetWithTTL("cold", 2, time.Minute)
+	cache.Get("cold")
+	cache.Get("cold")
+	assert.NotNil(t, cache.readOnly.Load())
+	for i := 0; i < 10; i++ {
+		cache.Get("hot")
+	}
+
+	meta, _ := cache.GetItemMeta("hot")
+	assert.Equal(t, int64(10), meta.Hits, "Expected the hits of the fast path to count for the item")
+	cache.mutex.Lock()
+	hot := cache.dueHotKeys(HotKeyRefresh{TopK: 1, RefreshAhead: 1}, time.Now())
+	cache.mutex.Unlock()
+	assert.Equal(t, []string{"hot"}, hot)
+}
+
+func TestCache_SetReadMostlyExpired(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetReadMostly(true)
+
+	cache.SetWithTTL("a", 1, 50*time.Millisecond)
+	cache.Get("a")
+	assert.NotNil(t, cache.readOnly.Load())
+
+	<-time.After(100 * time.Millisecond)
+	_, exists := cache.Get("a")
+	assert.False(t, exists, "Expected the snapshot to respect the expiration")
+}
+
+func TestCache_SetReadMostlyConcurrent(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetReadMostly(true)
+	for i := 0; i < 10; i++ {
+		cache.Set(strconv.Itoa(i), i)
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i % 10)
+				if worker == 0 && i%100 == 0 {
+					cache.Set(key, i%10)
+				}
+				data, exists := cache.Get(key)
+				assert.True(t, exists)
+				assert.Equal(t, i%10, data)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(4000), cache.GetMetrics().Hits)
+}
@@ -0,0 +1,40 @@
+package ttlcache
+
+// ReadOnlyView is a ReadOnlyViewOf a Cache
+type ReadOnlyView = ReadOnlyViewOf[string]
+
+// ReadOnlyViewOf gives access to a cache without the methods that change it, so it can be handed to plugins or
+// templates that must not modify shared state
+type ReadOnlyViewOf[K comparable] struct {
+	cache *CacheOf[K]
+}
+
+// ReadOnly returns a read-only view on the cache
+func (cache *CacheOf[K]) ReadOnly() ReadOnlyViewOf[K] {
+	return ReadOnlyViewOf[K]{cache: cache}
+}
+
+// Get looks up an item like Cache.Get, so it extends the TTL unless SkipTtlExtensionOnHit is set
+func (view ReadOnlyViewOf[K]) Get(key K) (interface{}, bool) {
+	return view.cache.Get(key)
+}
+
+// Peek looks up an item without extending its TTL or counting the lookup in the metrics
+func (view ReadOnlyViewOf[K]) Peek(key K) (interface{}, bool) {
+	return view.cache.Peek(key)
+}
+
+// Has reports whether the key exists and is not expired, see CacheOf.Has
+func (view ReadOnlyViewOf[K]) Has(key K) bool {
+	return view.cache.Has(key)
+}
+
+// Keys returns the keys of all items which are not expired, in no particular order
+func (view ReadOnlyViewOf[K]) Keys() []K {
+	return view.cache.Keys()
+}
+
+// Count returns the number of items in the cache
+func (view ReadOnlyViewOf[K]) Count() int {
+	return view.cache.Count()
+}
@@ -0,0 +1,28 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_ReadOnly(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	view := cache.ReadOnly()
+
+	cache.SetWithTTL("key", "value", time.Minute)
+	data, exists := view.Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	data, exists = view.Peek("key")
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	_, exists = view.Peek("missing")
+	assert.False(t, exists)
+	assert.True(t, view.Has("key"))
+	assert.Equal(t, []string{"key"}, view.Keys())
+	assert.Equal(t, 1, view.Count())
+	assert.Equal(t, int64(1), cache.GetMetrics().Retrievals, "Expected Peek to not count as a retrieval")
+}
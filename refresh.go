@@ -0,0 +1,50 @@
+package ttlcache
+
+import (
+	"context"
+	"time"
+)
+
+// SetRefreshAhead reloads an item in the background with the loader when it is read after the given fraction of its
+// TTL has passed since it was written, for instance 0.8 reloads an item with a TTL of a minute when it is read after
+// 48 seconds. Readers keep getting the current value meanwhile, so items that are read often never expire and cause
+// a miss. Zero disables it. Unlike SetHotKeyRefresh, only keys which are read are reloaded.
+func (cache *CacheOf[K]) SetRefreshAhead(threshold float64) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.refreshAhead = threshold
+	cache.invalidateReads()
+}
+
+// refreshIfDue starts a background reload of an item read after the refresh ahead threshold, unless one is running
+// already, the cache mutex must be held
+func (cache *CacheOf[K]) refreshIfDue(item *ItemOf[K]) {
+	if cache.refreshAhead <= 0 || cache.loader == nil || item.TTL <= 0 || item.writeExpireAt.IsZero() || cache.isShutDown {
+		return
+	}
+	writtenAt := item.writeExpireAt.Add(-item.TTL)
+	if cache.clock.Now().Sub(writtenAt) < time.Duration(cache.refreshAhead*float64(item.TTL)) {
+		return
+	}
+	if _, running := cache.refreshes[item.key]; running {
+		return
+	}
+	if cache.refreshes == nil {
+		cache.refreshes = make(map[K]struct{})
+	}
+	cache.refreshes[item.key] = struct{}{}
+	cache.workers.Add(1)
+	go cache.refresh(item.key, item.version, cache.loader, cache.retryPolicy)
+}
+
+// refresh reloads the key and stores the result only when the key still has the version the reload started from, so
+// it does not bring back a removed key or overwrite a newer value. Failures leave the current value until it expires.
+func (cache *CacheOf[K]) refresh(key K, version uint64, loader ResultLoaderFunc[K], policy RetryPolicy) {
+	defer cache.workers.Done()
+	if result, err := cache.load(context.Background(), key, loader, policy); err == nil {
+		cache.setIfVersion(key, result.Value, result.TTL, itemOptions[K]{cost: result.Cost}, version)
+	}
+	cache.mutex.Lock()
+	delete(cache.refreshes, key)
+	cache.mutex.Unlock()
+}
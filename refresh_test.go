@@ -0,0 +1,81 @@
+package ttlcache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetRefreshAhead(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	var loads int32
+	refreshed := make(chan struct{}, 10)
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(20 * time.Millisecond)
+		refreshed <- struct{}{}
+		return "fresh", 200 * time.Millisecond, nil
+	})
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetRefreshAhead(0.5)
+	cache.SetWithTTL("key", "stale", 200*time.Millisecond)
+
+	data, _ := cache.Get("key")
+	assert.Equal(t, "stale", data)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&loads), "Expected no refresh before the threshold")
+
+	time.Sleep(120 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		data, exists := cache.Get("key")
+		assert.True(t, exists)
+		assert.Equal(t, "stale", data, "Expected readers to get the current value during the refresh")
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the key to be refreshed")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads), "Expected a single refresh for concurrent reads")
+
+	time.Sleep(100 * time.Millisecond)
+	data, exists := cache.Get("key")
+	assert.True(t, exists, "Expected the refreshed item not to expire")
+	assert.Equal(t, "fresh", data)
+}
+
+func TestCache_SetRefreshAheadKeepsNewerWrites(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		started <- key
+		<-release
+		return "reloaded", time.Minute, nil
+	})
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetRefreshAhead(0.01)
+	cache.SetWithTTL("removed", "old", time.Second)
+	cache.SetWithTTL("rewritten", "old", time.Second)
+
+	time.Sleep(20 * time.Millisecond)
+	cache.Get("removed")
+	cache.Get("rewritten")
+	<-started
+	<-started
+	cache.Remove("removed")
+	cache.Set("rewritten", "new")
+	close(release)
+	cache.workers.Wait()
+
+	_, exists := cache.Get("removed")
+	assert.False(t, exists, "Expected the refresh not to bring back a removed key")
+	data, _ := cache.Get("rewritten")
+	assert.Equal(t, "new", data, "Expected the refresh not to overwrite a newer value")
+}
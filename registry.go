@@ -0,0 +1,100 @@
+package ttlcache
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry holds caches by name, so the parts of an application can share caches without passing them around
+type Registry struct {
+	mutex  sync.Mutex
+	caches map[string]*Cache
+}
+
+// DefaultRegistry is the Registry used by the package level GetOrCreate, AggregateMetrics and CloseAll
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{caches: make(map[string]*Cache)}
+}
+
+// GetOrCreate returns the cache registered under name, or creates and registers it with NewCache.
+// The setup functions only run for a new cache, before it is visible to other callers, so it can be configured
+// without races. They must not use the Registry.
+func (registry *Registry) GetOrCreate(name string, setup ...func(*Cache)) *Cache {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if cache, exists := registry.caches[name]; exists {
+		return cache
+	}
+	cache := NewCache()
+	for _, f := range setup {
+		f(cache)
+	}
+	registry.caches[name] = cache
+	return cache
+}
+
+// Lookup returns the cache registered under name
+func (registry *Registry) Lookup(name string) (*Cache, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	cache, exists := registry.caches[name]
+	return cache, exists
+}
+
+// Names returns the sorted names of all registered caches
+func (registry *Registry) Names() []string {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	names := make([]string, 0, len(registry.caches))
+	for name := range registry.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Metrics returns the sum of the metrics of all registered caches
+func (registry *Registry) Metrics() Metrics {
+	registry.mutex.Lock()
+	caches := make([]*Cache, 0, len(registry.caches))
+	for _, cache := range registry.caches {
+		caches = append(caches, cache)
+	}
+	registry.mutex.Unlock()
+
+	var total Metrics
+	for _, cache := range caches {
+		total.add(cache.GetMetrics())
+	}
+	return total
+}
+
+// CloseAll closes all registered caches and empties the Registry, call it at shutdown
+func (registry *Registry) CloseAll() {
+	registry.mutex.Lock()
+	caches := registry.caches
+	registry.caches = make(map[string]*Cache)
+	registry.mutex.Unlock()
+
+	for _, cache := range caches {
+		cache.Close()
+	}
+}
+
+// GetOrCreate returns the cache registered under name in the DefaultRegistry, see Registry.GetOrCreate
+func GetOrCreate(name string, setup ...func(*Cache)) *Cache {
+	return DefaultRegistry.GetOrCreate(name, setup...)
+}
+
+// AggregateMetrics returns the sum of the metrics of all caches in the DefaultRegistry
+func AggregateMetrics() Metrics {
+	return DefaultRegistry.Metrics()
+}
+
+// CloseAll closes all caches in the DefaultRegistry
+func CloseAll() {
+	DefaultRegistry.CloseAll()
+}
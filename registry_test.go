@@ -0,0 +1,45 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	setups := 0
+	setup := func(cache *Cache) {
+		setups++
+		cache.SetTTL(time.Minute)
+	}
+
+	sessions := registry.GetOrCreate("sessions", setup)
+	assert.Equal(t, sessions, registry.GetOrCreate("sessions", setup))
+	assert.Equal(t, 1, setups, "Expected the setup to only run for a new cache")
+	users := registry.GetOrCreate("users")
+	cache, exists := registry.Lookup("users")
+	assert.True(t, exists)
+	assert.Equal(t, users, cache)
+	assert.Equal(t, []string{"sessions", "users"}, registry.Names())
+
+	sessions.Set("a", 1)
+	sessions.Get("a")
+	users.Get("b")
+	metrics := registry.Metrics()
+	assert.Equal(t, int64(1), metrics.Inserted)
+	assert.Equal(t, int64(2), metrics.Retrievals)
+	assert.Equal(t, int64(1), metrics.Misses)
+
+	registry.CloseAll()
+	assert.Equal(t, ErrCacheClosed, sessions.Healthy())
+	assert.Empty(t, registry.Names())
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	cache := GetOrCreate("shared")
+	defer CloseAll()
+	cache.Set("key", "value")
+	assert.Equal(t, int64(1), AggregateMetrics().Inserted)
+}
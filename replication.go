@@ -0,0 +1,296 @@
+package ttlcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReplicationMessage is a single write exchanged between the replicas of a cluster
+type ReplicationMessage struct {
+	Key     string
+	Value   interface{}
+	TTL     time.Duration
+	Deleted bool
+	// Timestamp is the wall clock time of the write in nanoseconds, the latest write wins
+	Timestamp int64
+	// Origin is the id of the replica that did the write, it breaks ties between equal timestamps
+	Origin string
+}
+
+// newer reports whether the message wins over a write with the given version
+func (message ReplicationMessage) newer(version replicaVersion) bool {
+	if message.Timestamp != version.timestamp {
+		return message.Timestamp > version.timestamp
+	}
+	return message.Origin > version.origin
+}
+
+// ReplicationTransport delivers the writes of a replica to the other replicas of the cluster,
+// which hand them to Replica.Apply.
+type ReplicationTransport interface {
+	Broadcast(ctx context.Context, message ReplicationMessage) error
+}
+
+type replicaVersion struct {
+	timestamp int64
+	origin    string
+	deleted   bool
+}
+
+// Replica is an experimental replication layer on top of a Cache. Writes done through the Replica are sent to the
+// other replicas, and every replica keeps the write with the latest timestamp, so the cluster converges on the same
+// content once all messages are delivered. This suits small clusters caching feature flags or configuration,
+// it relies on reasonably synchronized clocks and does not replicate writes done on the Cache directly.
+// Values travel encoded with encoding/gob, register their types with gob.Register.
+type Replica struct {
+	cache     *Cache
+	id        string
+	transport ReplicationTransport
+
+	mutex    sync.Mutex
+	versions map[string]replicaVersion
+	// tombstoneTTL is how long removed keys are remembered, so late writes older than the removal are ignored
+	tombstoneTTL time.Duration
+	done         chan struct{}
+	stopped      chan struct{}
+}
+
+// NewReplica creates the replica with the given unique id for the cache. Close the Replica before the Cache.
+func NewReplica(cache *Cache, id string, transport ReplicationTransport) *Replica {
+	replica := &Replica{
+		cache:        cache,
+		id:           id,
+		transport:    transport,
+		versions:     make(map[string]replicaVersion),
+		tombstoneTTL: time.Minute,
+		done:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	go replica.forgetExpired(cache.Subscribe(1024))
+	return replica
+}
+
+// forgetExpired drops the versions of keys that expired or were purged, and tombstones that are old enough
+func (replica *Replica) forgetExpired(subscription *Subscription) {
+	defer close(replica.stopped)
+	defer subscription.Close()
+	ticker := time.NewTicker(replica.tombstoneTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-replica.done:
+			return
+		case event, open := <-subscription.C:
+			if !open {
+				return
+			}
+			if event.Type == EventExpired || event.Type == EventPurged {
+				replica.mutex.Lock()
+				if version, exists := replica.versions[event.Key]; exists && !version.deleted {
+					delete(replica.versions, event.Key)
+				}
+				replica.mutex.Unlock()
+			}
+		case now := <-ticker.C:
+			limit := now.Add(-replica.tombstoneTTL).UnixNano()
+			replica.mutex.Lock()
+			for key, version := range replica.versions {
+				if version.deleted && version.timestamp < limit {
+					delete(replica.versions, key)
+				}
+			}
+			replica.mutex.Unlock()
+		}
+	}
+}
+
+// Close stops the replica, the cache itself is left open
+func (replica *Replica) Close() {
+	select {
+	case <-replica.done:
+	default:
+		close(replica.done)
+	}
+	<-replica.stopped
+}
+
+// Get returns the value of a key from the local cache
+func (replica *Replica) Get(key string) (interface{}, bool) {
+	return replica.cache.Get(key)
+}
+
+// Set stores the value locally with the global TTL and sends it to the other replicas
+func (replica *Replica) Set(ctx context.Context, key string, data interface{}) error {
+	return replica.SetWithTTL(ctx, key, data, ItemExpireWithGlobalTTL)
+}
+
+// SetWithTTL stores the value locally and sends it to the other replicas
+func (replica *Replica) SetWithTTL(ctx context.Context, key string, data interface{}, ttl time.Duration) error {
+	return replica.write(ctx, ReplicationMessage{Key: key, Value: data, TTL: ttl})
+}
+
+// Remove removes the key locally and on the other replicas
+func (replica *Replica) Remove(ctx context.Context, key string) error {
+	return replica.write(ctx, ReplicationMessage{Key: key, Deleted: true})
+}
+
+func (replica *Replica) write(ctx context.Context, message ReplicationMessage) error {
+	message.Timestamp = time.Now().UnixNano()
+	message.Origin = replica.id
+	replica.mutex.Lock()
+	// keep the timestamps of a key increasing, even when the clock of another replica runs ahead
+	if version, exists := replica.versions[message.Key]; exists && !message.newer(version) {
+		message.Timestamp = version.timestamp + 1
+	}
+	replica.apply(message)
+	replica.mutex.Unlock()
+	return replica.transport.Broadcast(ctx, message)
+}
+
+// Apply stores a write received from another replica, unless a later write of the key is known already.
+// It reports whether the write was applied.
+func (replica *Replica) Apply(message ReplicationMessage) bool {
+	replica.mutex.Lock()
+	defer replica.mutex.Unlock()
+	if version, exists := replica.versions[message.Key]; exists && !message.newer(version) {
+		return false
+	}
+	return replica.apply(message)
+}
+
+// apply writes the message to the cache, the replica mutex must be held
+func (replica *Replica) apply(message ReplicationMessage) bool {
+	replica.versions[message.Key] = replicaVersion{timestamp: message.Timestamp, origin: message.Origin, deleted: message.Deleted}
+	if message.Deleted {
+		replica.cache.Remove(message.Key)
+		return true
+	}
+
+	ttl := message.TTL
+	if ttl > 0 {
+		// account for the time the message took to arrive
+		ttl -= time.Since(time.Unix(0, message.Timestamp))
+		if ttl <= 0 {
+			replica.cache.Remove(message.Key)
+			return true
+		}
+	}
+	replica.cache.SetWithTTL(message.Key, message.Value, ttl)
+	return true
+}
+
+// State returns the writes describing the current content of the replica, including the tombstones of removed keys.
+// A new replica catches up by applying the state of an existing one.
+func (replica *Replica) State() []ReplicationMessage {
+	replica.mutex.Lock()
+	defer replica.mutex.Unlock()
+	state := make([]ReplicationMessage, 0, len(replica.versions))
+	for key, version := range replica.versions {
+		message := ReplicationMessage{Key: key, Deleted: version.deleted, Timestamp: version.timestamp, Origin: version.origin}
+		if !version.deleted {
+			data, expireAt, exists := replica.cache.peek(key)
+			if !exists {
+				continue
+			}
+			message.Value = data
+			message.TTL = ItemNotExpire
+			if !expireAt.IsZero() {
+				// the remaining lifetime counted from the timestamp of the write
+				message.TTL = expireAt.Sub(time.Unix(0, version.timestamp))
+			}
+		}
+		state = append(state, message)
+	}
+	return state
+}
+
+// Handler returns an http.Handler for the HTTPReplicationTransport of other replicas: a POST applies a write,
+// a GET returns the State of this replica for Bootstrap.
+func (replica *Replica) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var message ReplicationMessage
+			if err := gob.NewDecoder(r.Body).Decode(&message); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			replica.Apply(message)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet:
+			if err := gob.NewEncoder(w).Encode(replica.State()); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// Bootstrap applies the state of the replica served by Handler at url, call it when joining a running cluster
+func (replica *Replica) Bootstrap(ctx context.Context, url string) error {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("ttlcache: bootstrap from %s: %s", url, response.Status)
+	}
+	var state []ReplicationMessage
+	if err := gob.NewDecoder(response.Body).Decode(&state); err != nil {
+		return err
+	}
+	for _, message := range state {
+		replica.Apply(message)
+	}
+	return nil
+}
+
+// HTTPReplicationTransport posts every write to the Handler of each peer
+type HTTPReplicationTransport struct {
+	// Peers are the URLs the handlers of the other replicas are served on
+	Peers []string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// Broadcast posts the message to all peers, it returns the first error but always tries every peer
+func (transport *HTTPReplicationTransport) Broadcast(ctx context.Context, message ReplicationMessage) error {
+	var body bytes.Buffer
+	if err := gob.NewEncoder(&body).Encode(message); err != nil {
+		return err
+	}
+	client := transport.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var firstErr error
+	for _, peer := range transport.Peers {
+		request, err := http.NewRequest(http.MethodPost, peer, bytes.NewReader(body.Bytes()))
+		if err == nil {
+			var response *http.Response
+			response, err = client.Do(request.WithContext(ctx))
+			if err == nil {
+				response.Body.Close()
+				if response.StatusCode != http.StatusNoContent {
+					err = fmt.Errorf("ttlcache: replicate to %s: %s", peer, response.Status)
+				}
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
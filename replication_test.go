@@ -0,0 +1,109 @@
+package ttlcache
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type localReplicationTransport struct {
+	peers []*Replica
+}
+
+func (transport *localReplicationTransport) Broadcast(ctx context.Context, message ReplicationMessage) error {
+	for _, peer := range transport.peers {
+		peer.Apply(message)
+	}
+	return nil
+}
+
+func TestReplica_LastWriterWins(t *testing.T) {
+	cacheA, cacheB := NewCache(), NewCache()
+	defer cacheA.Close()
+	defer cacheB.Close()
+	transportA, transportB := &localReplicationTransport{}, &localReplicationTransport{}
+	replicaA := NewReplica(cacheA, "a", transportA)
+	replicaB := NewReplica(cacheB, "b", transportB)
+	defer replicaA.Close()
+	defer replicaB.Close()
+	transportA.peers = []*Replica{replicaB}
+	transportB.peers = []*Replica{replicaA}
+
+	assert.Nil(t, replicaA.SetWithTTL(context.Background(), "flag", true, time.Minute))
+	data, exists := replicaB.Get("flag")
+	assert.True(t, exists)
+	assert.Equal(t, true, data)
+	ttl, _ := cacheB.GetTTL("flag")
+	assert.True(t, ttl > 59*time.Second && ttl <= time.Minute, "Expected the TTL to be replicated")
+
+	late := ReplicationMessage{Key: "flag", Value: false, Timestamp: time.Now().Add(-time.Hour).UnixNano(), Origin: "c"}
+	assert.False(t, replicaB.Apply(late), "Expected an older write to lose")
+	data, _ = replicaB.Get("flag")
+	assert.Equal(t, true, data)
+
+	assert.Nil(t, replicaB.Remove(context.Background(), "flag"))
+	_, exists = replicaA.Get("flag")
+	assert.False(t, exists)
+	late.Timestamp = time.Now().Add(-time.Second).UnixNano()
+	assert.False(t, replicaA.Apply(late), "Expected a removal to not be undone by an older write")
+
+	tie := ReplicationMessage{Key: "tie", Value: "a", Timestamp: 1, Origin: "a"}
+	replicaA.Apply(tie)
+	tie.Value, tie.Origin = "b", "b"
+	assert.True(t, replicaA.Apply(tie), "Expected the origin to break ties")
+}
+
+func TestReplica_HTTPTransport(t *testing.T) {
+	cacheA, cacheB := NewCache(), NewCache()
+	defer cacheA.Close()
+	defer cacheB.Close()
+	transportA := &HTTPReplicationTransport{}
+	replicaA := NewReplica(cacheA, "a", transportA)
+	defer replicaA.Close()
+	serverA := httptest.NewServer(replicaA.Handler())
+	defer serverA.Close()
+
+	assert.Nil(t, replicaA.Set(context.Background(), "before", "joined"))
+	replicaB := NewReplica(cacheB, "b", &HTTPReplicationTransport{Peers: []string{serverA.URL}})
+	defer replicaB.Close()
+	serverB := httptest.NewServer(replicaB.Handler())
+	defer serverB.Close()
+	transportA.Peers = []string{serverB.URL}
+
+	assert.Nil(t, replicaB.Bootstrap(context.Background(), serverA.URL))
+	data, _ := replicaB.Get("before")
+	assert.Equal(t, "joined", data)
+
+	assert.Nil(t, replicaA.SetWithTTL(context.Background(), "after", 42, time.Minute))
+	data, _ = replicaB.Get("after")
+	assert.Equal(t, 42, data)
+	assert.Nil(t, replicaB.Remove(context.Background(), "before"))
+	_, exists := replicaA.Get("before")
+	assert.False(t, exists)
+}
+
+func TestReplica_ForgetPurged(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	replica := NewReplica(cache, "a", &localReplicationTransport{})
+	defer replica.Close()
+
+	assert.Nil(t, replica.Set(context.Background(), "flag", true))
+	cache.Purge()
+	deadline := time.Now().Add(time.Second)
+	for {
+		replica.mutex.Lock()
+		_, known := replica.versions["flag"]
+		replica.mutex.Unlock()
+		if !known || time.Now().After(deadline) {
+			assert.False(t, known, "Expected the version of a purged key to be forgotten")
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	older := ReplicationMessage{Key: "flag", Value: false, Timestamp: time.Now().Add(-time.Hour).UnixNano(), Origin: "b"}
+	assert.True(t, replica.Apply(older), "Expected a write to a purged key to be applied")
+}
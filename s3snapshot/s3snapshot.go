@@ -0,0 +1,165 @@
+// Package s3snapshot is an example ttlcache.SnapshotStorage for S3 compatible object stores, such as AWS S3 or MinIO.
+// It only depends on the standard library and signs its requests with AWS Signature Version 4.
+//
+//	storage := &s3snapshot.Storage{
+//		Endpoint:        "https://s3.eu-west-1.amazonaws.com",
+//		Region:          "eu-west-1",
+//		Bucket:          "my-bucket",
+//		Key:             "snapshots/sessions.gob",
+//		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+//		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+//	}
+//	if err := cache.RestoreSnapshot(ctx, storage); err != nil {
+//		log.Printf("starting cold: %v", err)
+//	}
+//	cache.StartSnapshotUploads(storage, time.Minute, func(err error) { log.Print(err) })
+package s3snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jadevelopmentgrp/TTLCache"
+)
+
+// Storage stores the snapshot as a single object, addressed path style as Endpoint/Bucket/Key
+type Storage struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Key             string
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is only needed for temporary credentials
+	SessionToken string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+
+	now func() time.Time
+}
+
+// Put uploads the snapshot, replacing the previous one
+func (s *Storage) Put(ctx context.Context, snapshot io.Reader) error {
+	body, err := ioutil.ReadAll(snapshot)
+	if err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodPut, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return statusError(response)
+	}
+	return nil
+}
+
+// Get downloads the latest snapshot, ttlcache.ErrNoSnapshot is returned when the object does not exist
+func (s *Storage) Get(ctx context.Context) (io.ReadCloser, error) {
+	response, err := s.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.Body, nil
+	case http.StatusNotFound:
+		response.Body.Close()
+		return nil, ttlcache.ErrNoSnapshot
+	default:
+		defer response.Body.Close()
+		return nil, statusError(response)
+	}
+}
+
+func (s *Storage) do(ctx context.Context, method string, body []byte) (*http.Response, error) {
+	target, err := url.Parse(strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + strings.TrimPrefix(s.Key, "/"))
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequest(method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	if body == nil {
+		request.Body = http.NoBody
+	}
+	s.sign(request, body)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(request)
+}
+
+// sign adds the AWS Signature Version 4 headers to the request
+func (s *Storage) sign(request *http.Request, body []byte) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	timestamp := now().UTC().Format("20060102T150405Z")
+	date := timestamp[:8]
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", timestamp)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + timestamp + "\n"
+	if s.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.SessionToken)
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + s.SessionToken + "\n"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + timestamp + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func statusError(response *http.Response) error {
+	message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 512))
+	return fmt.Errorf("s3snapshot: %s %s: %s: %s", response.Request.Method, response.Request.URL.Path, response.Status, bytes.TrimSpace(message))
+}
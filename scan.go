@@ -0,0 +1,103 @@
+package ttlcache
+
+import (
+	"sort"
+)
+
+// defaultScanCount is the count of Scan when none is given
+const defaultScanCount = 10
+
+// Scan returns up to count keys starting at the cursor, and the cursor of the next call, which is 0 once the scan is
+// complete. Start with cursor 0. Like the SCAN command of Redis, every key stored for the whole scan is returned
+// exactly once and keys added or removed during the scan may or may not be returned. Each call locks the cache only
+// for the keys it returns, so exporters and admin tools can walk huge caches in small steps. A count of 0 or less
+// means the default of Redis, 10.
+func (cache *CacheOf[K]) Scan(cursor uint64, count int) ([]K, uint64) {
+	if count <= 0 {
+		count = defaultScanCount
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	// the scan order is the order of insertion, so the sequence numbers of the items serve as cursors
+	start := sort.Search(len(cache.scanItems), func(i int) bool { return cache.scanItems[i].seq >= cursor })
+	var keys []K
+	for i := start; i < len(cache.scanItems); i++ {
+		item := cache.scanItems[i]
+		if len(keys) == count {
+			return keys, item.seq
+		}
+		if current, exists := cache.items.Get(item.key); exists && current == item && !item.expired() {
+			keys = append(keys, item.key)
+		}
+	}
+	return keys, 0
+}
+
+// ScanIterator is a ScanIteratorOf a Cache
+type ScanIterator = ScanIteratorOf[string]
+
+// ScanIteratorOf pulls the keys of a cache in chunks with Scan, see Iterate
+type ScanIteratorOf[K comparable] struct {
+	cache  *CacheOf[K]
+	count  int
+	cursor uint64
+	keys   []K
+	key    K
+	done   bool
+}
+
+// Iterate returns an iterator over the keys which fetches count keys at a time with Scan, so writers are only blocked
+// for one chunk at a time. It gives the same guarantees as Scan and uses its default for a count of 0 or less.
+//
+//	for it := cache.Iterate(100); it.Next(); {
+//		export(it.Key())
+//	}
+func (cache *CacheOf[K]) Iterate(count int) *ScanIteratorOf[K] {
+	return &ScanIteratorOf[K]{cache: cache, count: count}
+}
+
+// Next advances to the next key and reports whether there is one
+func (it *ScanIteratorOf[K]) Next() bool {
+	for len(it.keys) == 0 {
+		if it.done {
+			return false
+		}
+		it.keys, it.cursor = it.cache.Scan(it.cursor, it.count)
+		it.done = it.cursor == 0
+	}
+	it.key, it.keys = it.keys[0], it.keys[1:]
+	return true
+}
+
+// Key returns the current key
+func (it *ScanIteratorOf[K]) Key() K {
+	return it.key
+}
+
+// addToScan appends a new item to the scan order, the cache mutex must be held
+func (cache *CacheOf[K]) addToScan(item *ItemOf[K]) {
+	cache.lastSeq++
+	item.seq = cache.lastSeq
+	cache.scanItems = append(cache.scanItems, item)
+}
+
+// removeFromScan counts a removed item, which Scan skips, and drops the removed items from the scan order once they
+// are the majority, the cache mutex must be held
+func (cache *CacheOf[K]) removeFromScan() {
+	cache.scanRemoved++
+	if cache.scanRemoved > len(cache.scanItems)/2 {
+		cache.compactScan()
+	}
+}
+
+// compactScan drops the removed items from the scan order, the cache mutex must be held
+func (cache *CacheOf[K]) compactScan() {
+	scanItems := make([]*ItemOf[K], 0, cache.items.Len())
+	for _, item := range cache.scanItems {
+		if current, exists := cache.items.Get(item.key); exists && current == item {
+			scanItems = append(scanItems, item)
+		}
+	}
+	cache.scanItems = scanItems
+	cache.scanRemoved = 0
+}
@@ -0,0 +1,92 @@
+package ttlcache
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Scan(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	for i := 0; i < 100; i++ {
+		cache.Set(Key(i), i)
+	}
+	seen := map[string]int{}
+	var cursor uint64
+	for steps := 0; ; steps++ {
+		keys, next := cache.Scan(cursor, 7)
+		assert.True(t, len(keys) <= 7)
+		for _, key := range keys {
+			seen[key]++
+		}
+		if steps == 3 {
+			// changes during the scan do not disturb it
+			for i := 0; i < 60; i++ {
+				cache.Remove(Key(i))
+			}
+			cache.Set(Key(0), 0)
+			cache.Set("new", "value")
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	for i := 60; i < 100; i++ {
+		assert.Equal(t, 1, seen[Key(i)], "Expected every key stored for the whole scan exactly once")
+	}
+	assert.Equal(t, 1, seen["new"], "Expected keys added during the scan to come after the cursor")
+
+	keys, next := cache.Scan(0, 1000)
+	assert.Equal(t, uint64(0), next)
+	sort.Strings(keys)
+	assert.Len(t, keys, 42)
+	cache.mutex.Lock()
+	assert.True(t, len(cache.scanItems) < 100, "Expected the removed items to be dropped from the scan order")
+	cache.mutex.Unlock()
+}
+
+func TestCache_ScanDefaultCount(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	for i := 0; i < 25; i++ {
+		cache.Set(Key(i), i)
+	}
+	for _, count := range []int{0, -1} {
+		var sizes []int
+		var cursor uint64
+		for {
+			keys, next := cache.Scan(cursor, count)
+			sizes = append(sizes, len(keys))
+			if next == 0 {
+				break
+			}
+			assert.NotEqual(t, cursor, next, "Expected the scan to advance")
+			cursor = next
+		}
+		assert.Equal(t, []int{10, 10, 5}, sizes, "Expected a count of %d to mean 10", count)
+	}
+}
+
+func TestCache_Iterate(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	it := cache.Iterate(3)
+	assert.False(t, it.Next())
+
+	for i := 0; i < 10; i++ {
+		cache.Set(Key(i), i)
+	}
+	var keys []string
+	for it := cache.Iterate(3); it.Next(); {
+		keys = append(keys, it.Key())
+	}
+	assert.Len(t, keys, 10)
+	sort.Strings(keys)
+	assert.Equal(t, Key(0), keys[0])
+}
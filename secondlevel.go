@@ -0,0 +1,68 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by GetOrLoad when the second level does not have a key and there is no loader
+var ErrNotFound = errors.New("ttlcache: key not found")
+
+// SecondLevel is a slower cache shared between processes, such as Redis or memcached, which GetOrLoad reads through
+// before calling the loader
+type SecondLevel[K comparable] interface {
+	// Fetch returns the value of a key and its remaining TTL, found is false when the key is not stored
+	Fetch(ctx context.Context, key K) (data interface{}, ttl time.Duration, found bool, err error)
+}
+
+// fetchCall is a running fetch from the second level, the result is set before done is closed
+type fetchCall struct {
+	done  chan struct{}
+	data  interface{}
+	ttl   time.Duration
+	found bool
+	err   error
+}
+
+// SetSecondLevel sets the second level GetOrLoad reads missing keys from. Concurrent misses of the same key share a
+// single fetch and its result, also when the key is not found, so a cold cache does not flood the second level.
+func (cache *CacheOf[K]) SetSecondLevel(level SecondLevel[K]) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.secondLevel = level
+}
+
+// fetch reads the key from the second level, joining a running fetch of the same key
+func (cache *CacheOf[K]) fetch(ctx context.Context, level SecondLevel[K], key K) (interface{}, time.Duration, bool, error) {
+	key = cache.normalizeKey(key)
+	cache.mutex.Lock()
+	call, running := cache.fetches[key]
+	if !running {
+		// the error stays when Fetch panics
+		call = &fetchCall{done: make(chan struct{}), err: ErrLoaderPanicked}
+		if cache.fetches == nil {
+			cache.fetches = make(map[K]*fetchCall)
+		}
+		cache.fetches[key] = call
+	}
+	cache.mutex.Unlock()
+
+	if running {
+		select {
+		case <-call.done:
+			return call.data, call.ttl, call.found, call.err
+		case <-ctx.Done():
+			return nil, 0, false, ctx.Err()
+		}
+	}
+
+	defer func() {
+		cache.mutex.Lock()
+		delete(cache.fetches, key)
+		cache.mutex.Unlock()
+		close(call.done)
+	}()
+	call.data, call.ttl, call.found, call.err = level.Fetch(ctx, key)
+	return call.data, call.ttl, call.found, call.err
+}
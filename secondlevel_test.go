@@ -0,0 +1,88 @@
+package ttlcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowSecondLevel struct {
+	fetches int32
+	values  map[string]string
+}
+
+func (level *slowSecondLevel) Fetch(ctx context.Context, key string) (interface{}, time.Duration, bool, error) {
+	atomic.AddInt32(&level.fetches, 1)
+	time.Sleep(20 * time.Millisecond)
+	value, found := level.values[key]
+	return value, time.Minute, found, nil
+}
+
+func TestCache_SetSecondLevel(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	level := &slowSecondLevel{values: map[string]string{"shared": "value"}}
+	cache.SetSecondLevel(level)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			data, err := cache.GetOrLoad(context.Background(), "shared")
+			assert.Nil(t, err)
+			assert.Equal(t, "value", data)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetOrLoad(context.Background(), "missing")
+			assert.Equal(t, ErrNotFound, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&level.fetches), "Expected one fetch per key, also for misses")
+	assert.True(t, cache.Has("shared"))
+
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return "loaded", 0, nil
+	})
+	data, err := cache.GetOrLoad(context.Background(), "missing")
+	assert.Nil(t, err)
+	assert.Equal(t, "loaded", data, "Expected the loader after a second level miss")
+}
+
+type panickingSecondLevel struct {
+	panics bool
+}
+
+func (level *panickingSecondLevel) Fetch(ctx context.Context, key string) (interface{}, time.Duration, bool, error) {
+	if level.panics {
+		panic("broken second level")
+	}
+	return "value", time.Minute, true, nil
+}
+
+func TestCache_SetSecondLevelPanic(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	level := &panickingSecondLevel{panics: true}
+	cache.SetSecondLevel(level)
+
+	func() {
+		defer func() {
+			assert.Equal(t, "broken second level", recover(), "Expected the panic to reach the caller")
+		}()
+		_, _ = cache.GetOrLoad(context.Background(), "key")
+	}()
+
+	level.panics = false
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	data, err := cache.GetOrLoad(ctx, "key")
+	assert.Nil(t, err, "Expected the panicked fetch to be released")
+	assert.Equal(t, "value", data)
+}
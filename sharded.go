@@ -0,0 +1,139 @@
+package ttlcache
+
+import (
+	"runtime"
+	"time"
+)
+
+// ShardedCache is a ShardedCacheOf with string keys
+type ShardedCache = ShardedCacheOf[string]
+
+// ShardedCacheOf spreads its keys over several caches, the shards, each with its own map, lock and expiration queue,
+// so concurrent Get and Set calls for different keys rarely wait for each other. Limits and callbacks are set per
+// shard, see Shards: a size limit set with SetCacheSizeLimit or a cost limit set with SetWeigher bounds every shard on
+// its own, so the whole cache holds up to the number of shards times the limit.
+type ShardedCacheOf[K comparable] struct {
+	shards     []*CacheOf[K]
+	hash       func(key K) uint64
+	normalizer func(key K) K
+}
+
+// NewShardedCache creates a ShardedCache with the given number of shards, or one per CPU when shards is not positive
+func NewShardedCache(shards int) *ShardedCache {
+	return NewShardedCacheOf[string](shards, hashString)
+}
+
+// NewShardedCacheOf creates a ShardedCacheOf with the given number of shards, or one per CPU when shards is not
+// positive. The hash function spreads the keys over the shards.
+func NewShardedCacheOf[K comparable](shards int, hash func(key K) uint64) *ShardedCacheOf[K] {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	sharded := &ShardedCacheOf[K]{shards: make([]*CacheOf[K], shards), hash: hash}
+	for i := range sharded.shards {
+		sharded.shards[i] = NewCacheOf[K]()
+	}
+	return sharded
+}
+
+// hashString is the FNV-1a hash of the key
+func hashString(key string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= 1099511628211
+	}
+	return hash
+}
+
+// Shard returns the shard the key is stored in, chosen by the hash of the normalized key
+func (sharded *ShardedCacheOf[K]) Shard(key K) *CacheOf[K] {
+	if sharded.normalizer != nil {
+		key = sharded.normalizer(key)
+	}
+	return sharded.shards[sharded.hash(key)%uint64(len(sharded.shards))]
+}
+
+// SetKeyNormalizer sets the key normalizer of all shards, see CacheOf.SetKeyNormalizer, and picks the shard of a key
+// by its normalized form, so keys which normalize to the same key share a shard. Set it before adding items, and
+// not on the shards themselves.
+func (sharded *ShardedCacheOf[K]) SetKeyNormalizer(normalizer func(key K) K) {
+	sharded.normalizer = normalizer
+	for _, shard := range sharded.shards {
+		shard.SetKeyNormalizer(normalizer)
+	}
+}
+
+// Shards returns all shards, for instance to configure them
+func (sharded *ShardedCacheOf[K]) Shards() []*CacheOf[K] {
+	return sharded.shards
+}
+
+// Set stores the item with the global TTL of its shard
+func (sharded *ShardedCacheOf[K]) Set(key K, data interface{}) {
+	sharded.Shard(key).Set(key, data)
+}
+
+// SetWithTTL stores the item with an individual TTL
+func (sharded *ShardedCacheOf[K]) SetWithTTL(key K, data interface{}, ttl time.Duration) {
+	sharded.Shard(key).SetWithTTL(key, data, ttl)
+}
+
+// Get looks up an item
+func (sharded *ShardedCacheOf[K]) Get(key K) (interface{}, bool) {
+	return sharded.Shard(key).Get(key)
+}
+
+// Has reports whether the key exists and is not expired, see CacheOf.Has
+func (sharded *ShardedCacheOf[K]) Has(key K) bool {
+	return sharded.Shard(key).Has(key)
+}
+
+// GetTTL returns the TTL of the key
+func (sharded *ShardedCacheOf[K]) GetTTL(key K) (time.Duration, bool) {
+	return sharded.Shard(key).GetTTL(key)
+}
+
+// Remove removes the key and reports whether it was stored
+func (sharded *ShardedCacheOf[K]) Remove(key K) bool {
+	return sharded.Shard(key).Remove(key)
+}
+
+// SetTTL sets the global TTL of all shards
+func (sharded *ShardedCacheOf[K]) SetTTL(ttl time.Duration) {
+	for _, shard := range sharded.shards {
+		shard.SetTTL(ttl)
+	}
+}
+
+// Count returns the number of items in all shards
+func (sharded *ShardedCacheOf[K]) Count() int {
+	count := 0
+	for _, shard := range sharded.shards {
+		count += shard.Count()
+	}
+	return count
+}
+
+// GetMetrics returns the sum of the metrics of the shards, QueueMaxLength is the largest of them
+func (sharded *ShardedCacheOf[K]) GetMetrics() Metrics {
+	var total Metrics
+	for _, shard := range sharded.shards {
+		total.add(shard.GetMetrics())
+	}
+	return total
+}
+
+// Purge removes all items of all shards
+func (sharded *ShardedCacheOf[K]) Purge() {
+	for _, shard := range sharded.shards {
+		shard.Purge()
+	}
+}
+
+// Close closes all shards
+func (sharded *ShardedCacheOf[K]) Close() {
+	for _, shard := range sharded.shards {
+		shard.Close()
+	}
+}
@@ -0,0 +1,64 @@
+package ttlcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedCache(t *testing.T) {
+	cache := NewShardedCache(8)
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := strconv.Itoa(worker*100 + i)
+				cache.SetWithTTL(key, i, time.Minute)
+				data, exists := cache.Get(key)
+				assert.True(t, exists)
+				assert.Equal(t, i, data)
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 800, cache.Count())
+	used := 0
+	for _, shard := range cache.Shards() {
+		if shard.Count() > 0 {
+			used++
+		}
+	}
+	assert.Equal(t, 8, used, "Expected the keys to be spread over all shards")
+	assert.Equal(t, int64(800), cache.GetMetrics().Hits)
+	ttl, _ := cache.GetTTL("42")
+	assert.Equal(t, time.Minute, ttl)
+	assert.True(t, cache.Remove("42"))
+	assert.False(t, cache.Shard("42").Has("42"))
+	assert.True(t, cache.Has("43"))
+	cache.Purge()
+	assert.Equal(t, 0, cache.Count())
+}
+
+func TestShardedCache_SetKeyNormalizer(t *testing.T) {
+	sharded := NewShardedCache(16)
+	defer sharded.Close()
+	sharded.SetKeyNormalizer(FoldKeyCase)
+
+	for i := 0; i < 100; i++ {
+		sharded.Set("Key"+strconv.Itoa(i), i)
+	}
+	for i := 0; i < 100; i++ {
+		data, exists := sharded.Get("KEY" + strconv.Itoa(i))
+		assert.True(t, exists, "Expected keys which normalize to the same key to share a shard")
+		assert.Equal(t, i, data)
+	}
+	assert.Equal(t, 100, sharded.Count())
+}
@@ -0,0 +1,35 @@
+package ttlcache
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// CloseOnSignal closes the cache once one of the signals is received, or when ctx is done. Without signals
+// it listens for SIGINT and SIGTERM, which is what Kubernetes sends before killing a pod. Closing stops all
+// background goroutines, snapshot uploads started with StartSnapshotUploads write a final snapshot first.
+// The returned channel is closed when the cache is closed, so main can wait for a graceful shutdown:
+//
+//	<-cache.CloseOnSignal(context.Background())
+func (cache *CacheOf[K]) CloseOnSignal(ctx context.Context, signals ...os.Signal) <-chan struct{} {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		defer signal.Stop(received)
+		select {
+		case <-received:
+		case <-ctx.Done():
+		case <-cache.done:
+		}
+		cache.Close()
+	}()
+	return closed
+}
@@ -0,0 +1,43 @@
+package ttlcache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_CloseOnSignal(t *testing.T) {
+	storage := &memorySnapshotStorage{}
+	cache := NewCache()
+	cache.StartSnapshotUploads(storage, time.Hour, nil)
+	closed := cache.CloseOnSignal(context.Background(), os.Interrupt)
+	cache.Set("key", "value")
+
+	process, err := os.FindProcess(os.Getpid())
+	assert.Nil(t, err)
+	assert.Nil(t, process.Signal(os.Interrupt))
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the cache to be closed on the signal")
+	}
+	assert.Equal(t, 1, storage.puts, "Expected a final snapshot on close")
+	assert.Equal(t, 0, cache.Count())
+}
+
+func TestCache_CloseOnSignalContext(t *testing.T) {
+	cache := NewCache()
+	ctx, cancel := context.WithCancel(context.Background())
+	closed := cache.CloseOnSignal(ctx)
+	cancel()
+	<-closed
+
+	cache = NewCache()
+	closed = cache.CloseOnSignal(context.Background())
+	cache.Close()
+	<-closed
+}
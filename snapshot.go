@@ -0,0 +1,150 @@
+package ttlcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNoSnapshot is returned by a SnapshotStorage when no snapshot was stored yet
+var ErrNoSnapshot = errors.New("ttlcache: no snapshot available")
+
+// SnapshotStorage keeps the latest snapshot of a cache outside of the process, for instance in an object store,
+// so new instances can start with a warm cache.
+type SnapshotStorage interface {
+	// Put replaces the latest snapshot with the content of the reader
+	Put(ctx context.Context, snapshot io.Reader) error
+	// Get returns the latest snapshot, or ErrNoSnapshot when there is none
+	Get(ctx context.Context) (io.ReadCloser, error)
+}
+
+// snapshotEntry is the serialized form of an Item
+type snapshotEntry struct {
+	Key       string
+	Data      interface{}
+	TTL       time.Duration
+	ExpireAt  time.Time
+	Validator string
+}
+
+// WriteSnapshot writes all items which are not expired to w, encoded with encoding/gob.
+// Values are stored as interface{}, so their concrete types must be registered with gob.Register,
+// builtin types like string and int are registered already.
+func (cache *Cache) WriteSnapshot(w io.Writer) error {
+	cache.mutex.Lock()
+	entries := make([]snapshotEntry, 0, cache.items.Len())
+	cache.items.Range(func(key string, item *Item) bool {
+		if item.expired() {
+			return true
+		}
+		entries = append(entries, snapshotEntry{Key: key, Data: item.Data, TTL: item.TTL, ExpireAt: item.ExpireAt,
+			Validator: item.validator})
+		return true
+	})
+	cache.mutex.Unlock()
+
+	return gob.NewEncoder(w).Encode(entries)
+}
+
+// ReadSnapshot adds the items of a snapshot written by WriteSnapshot. Items keep the expiration time they had
+// when the snapshot was taken, items which expired in the meantime and keys already in the cache are skipped.
+// The items are stored like any other write: keys are normalized, values weighed and the new item callback is called.
+func (cache *Cache) ReadSnapshot(r io.Reader) error {
+	var entries []snapshotEntry
+	if err := gob.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+
+	cache.mutex.Lock()
+	if cache.isShutDown {
+		cache.mutex.Unlock()
+		return ErrCacheClosed
+	}
+	now := cache.clock.Now()
+	newItemCallback := cache.newItemCallback
+	var added []snapshotEntry
+	for _, entry := range entries {
+		if entry.TTL > 0 && entry.ExpireAt.Before(now) {
+			continue
+		}
+		if item, exists := cache.items.Get(cache.normalize(entry.Key)); exists && !item.expired() {
+			continue
+		}
+		options := itemOptions[string]{validator: entry.Validator}
+		if entry.TTL > 0 {
+			options.expireAt = entry.ExpireAt
+		}
+		key, isNew := cache.set(entry.Key, entry.Data, entry.TTL, options)
+		if isNew && newItemCallback != nil {
+			added = append(added, snapshotEntry{Key: key, Data: entry.Data})
+		}
+	}
+	cache.mutex.Unlock()
+	for _, entry := range added {
+		newItemCallback(entry.Key, entry.Data)
+	}
+	cache.notifyExpiration()
+	return nil
+}
+
+// UploadSnapshot writes a snapshot of the cache to the storage
+func (cache *Cache) UploadSnapshot(ctx context.Context, storage SnapshotStorage) error {
+	var buffer bytes.Buffer
+	if err := cache.WriteSnapshot(&buffer); err != nil {
+		return err
+	}
+	return storage.Put(ctx, &buffer)
+}
+
+// RestoreSnapshot reads the latest snapshot from the storage into the cache, call it once at boot.
+// Having no snapshot in the storage yet is not an error.
+func (cache *Cache) RestoreSnapshot(ctx context.Context, storage SnapshotStorage) error {
+	snapshot, err := storage.Get(ctx)
+	if err == ErrNoSnapshot {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+	return cache.ReadSnapshot(snapshot)
+}
+
+// finalSnapshotTimeout bounds the upload of the final snapshot, which Close waits for
+const finalSnapshotTimeout = 10 * time.Second
+
+// StartSnapshotUploads uploads a snapshot to the storage every interval until the cache is closed.
+// Close waits for a final upload, so a restarted instance starts with the latest content. An upload may take up to
+// the interval, the final one up to 10 seconds, so a long interval does not hold up a shutdown.
+// Failed uploads are reported to onError, which may be nil.
+func (cache *Cache) StartSnapshotUploads(storage SnapshotStorage, interval time.Duration, onError func(error)) {
+	upload := func(timeout time.Duration) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := cache.UploadSnapshot(ctx, storage)
+		cache.mutex.Lock()
+		cache.snapshotErr = err
+		cache.mutex.Unlock()
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	cache.workers.Add(1)
+	go func() {
+		defer cache.workers.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cache.done:
+				upload(finalSnapshotTimeout)
+				return
+			case <-ticker.C:
+				upload(interval)
+			}
+		}
+	}()
+}
@@ -0,0 +1,154 @@
+package ttlcache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySnapshotStorage struct {
+	lock     sync.Mutex
+	snapshot []byte
+	puts     int
+	// deadline is the deadline of the context of the last put
+	deadline time.Time
+}
+
+func (storage *memorySnapshotStorage) Put(ctx context.Context, snapshot io.Reader) error {
+	data, err := ioutil.ReadAll(snapshot)
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+	storage.snapshot = data
+	storage.puts++
+	storage.deadline, _ = ctx.Deadline()
+	return err
+}
+
+func (storage *memorySnapshotStorage) Get(ctx context.Context) (io.ReadCloser, error) {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+	if storage.snapshot == nil {
+		return nil, ErrNoSnapshot
+	}
+	return ioutil.NopCloser(bytes.NewReader(storage.snapshot)), nil
+}
+
+func TestCache_Snapshot(t *testing.T) {
+	storage := &memorySnapshotStorage{}
+
+	cache := NewCache()
+	defer cache.Close()
+	assert.Nil(t, cache.RestoreSnapshot(context.Background(), storage), "Expected a missing snapshot to not be an error")
+
+	cache.SetWithTTL("short", "value", 50*time.Millisecond)
+	cache.SetWithTTL("long", 42, time.Hour)
+	cache.SetWithTTL("forever", "value", ItemNotExpire)
+	assert.Nil(t, cache.UploadSnapshot(context.Background(), storage))
+	_, expireAt, _ := cache.peek("long")
+
+	<-time.After(100 * time.Millisecond)
+	restored := NewCache()
+	defer restored.Close()
+	restored.Set("forever", "newer")
+	assert.Nil(t, restored.RestoreSnapshot(context.Background(), storage))
+
+	assert.Equal(t, 2, restored.Count(), "Expected the expired item to be skipped")
+	data, restoredExpireAt, _ := restored.peek("long")
+	assert.Equal(t, 42, data)
+	assert.True(t, expireAt.Equal(restoredExpireAt), "Expected the expiration time to be restored")
+	data, _ = restored.Get("forever")
+	assert.Equal(t, "newer", data, "Expected existing keys to win over the snapshot")
+}
+
+func TestCache_SnapshotRestoresLikeWrites(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("Key", "value", time.Hour)
+	var buffer bytes.Buffer
+	assert.Nil(t, cache.WriteSnapshot(&buffer))
+
+	restored := NewCache()
+	defer restored.Close()
+	restored.SetKeyNormalizer(FoldKeyCase)
+	restored.SetWeigher(func(key string, value interface{}) int64 {
+		return int64(len(value.(string)))
+	}, 100)
+	var added []string
+	restored.SetNewItemCallback(func(key string, value interface{}) {
+		added = append(added, key)
+	})
+	assert.Nil(t, restored.ReadSnapshot(&buffer))
+
+	assert.Equal(t, []string{"key"}, added)
+	assert.True(t, restored.Has("KEY"), "Expected the restored key to be normalized")
+	restored.mutex.Lock()
+	assert.Equal(t, int64(5), restored.totalCost)
+	restored.mutex.Unlock()
+	ttl, _ := restored.GetTTL("key")
+	assert.Equal(t, time.Hour, ttl)
+	_, expireAt, _ := restored.peek("key")
+	assert.InDelta(t, time.Hour, time.Until(expireAt), float64(time.Minute), "Expected the remaining TTL to be kept")
+}
+
+func TestCache_SnapshotWriteExpiration(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("key", "value", time.Hour)
+	var buffer bytes.Buffer
+	assert.Nil(t, cache.WriteSnapshot(&buffer))
+
+	restored := NewCache()
+	defer restored.Close()
+	restored.SetMaxTTLExtension(time.Minute)
+	loads := 0
+	restored.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		loads++
+		return "loaded", time.Hour, nil
+	})
+	restored.SetRefreshAhead(0.5)
+	assert.Nil(t, restored.ReadSnapshot(&buffer))
+
+	for i := 0; i < 3; i++ {
+		data, exists := restored.Get("key")
+		assert.True(t, exists, "Expected a restored item to survive its reads")
+		assert.Equal(t, "value", data)
+	}
+	restored.Close()
+	assert.Equal(t, 0, loads, "Expected a fresh restored item not to be refreshed")
+}
+
+func TestCache_StartSnapshotUploads(t *testing.T) {
+	storage := &memorySnapshotStorage{}
+
+	cache := NewCache()
+	cache.Set("key", "value")
+	cache.StartSnapshotUploads(storage, 10*time.Millisecond, func(err error) {
+		t.Error(err)
+	})
+	<-time.After(55 * time.Millisecond)
+	cache.Close()
+
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+	assert.True(t, storage.puts >= 3, "Expected a snapshot upload every interval")
+}
+
+func TestCache_StartSnapshotUploadsFinalTimeout(t *testing.T) {
+	storage := &memorySnapshotStorage{}
+	cache := NewCache()
+	cache.StartSnapshotUploads(storage, 24*time.Hour, nil)
+	closedAt := time.Now()
+	cache.Close()
+
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+	assert.Equal(t, 1, storage.puts)
+	assert.True(t, storage.deadline.Before(closedAt.Add(time.Minute)),
+		"Expected the final upload to be bounded independently of the interval")
+}
@@ -0,0 +1,69 @@
+package ttlcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	sseBuffer    = 256
+	sseKeepAlive = 15 * time.Second
+)
+
+type sseEvent struct {
+	Type  string      `json:"type"`
+	Key   string      `json:"key"`
+	Value interface{} `json:"value,omitempty"`
+	Time  time.Time   `json:"time"`
+}
+
+// EventsHandler returns an http.Handler streaming the events of the cache as Server-Sent Events, so dashboards
+// can follow the cache in a browser with an EventSource. The optional prefix query parameter limits the stream to
+// keys with that prefix. Every message has the event type as name and a JSON object with type, key, value and time
+// as data, values which can not be encoded as JSON are sent formatted with %v.
+// Slow clients miss events rather than slowing down the cache, see Subscribe.
+func (cache *Cache) EventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		prefix := r.URL.Query().Get("prefix")
+
+		subscription := cache.Subscribe(sseBuffer)
+		defer subscription.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			case event, open := <-subscription.C:
+				if !open {
+					return
+				}
+				if !strings.HasPrefix(event.Key, prefix) {
+					continue
+				}
+				data, err := json.Marshal(sseEvent{Type: event.Type.String(), Key: event.Key, Value: event.Value, Time: event.Time})
+				if err != nil {
+					data, _ = json.Marshal(sseEvent{Type: event.Type.String(), Key: event.Key, Value: fmt.Sprintf("%v", event.Value), Time: event.Time})
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			}
+			flusher.Flush()
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package ttlcache
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_EventsHandler(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	server := httptest.NewServer(cache.EventsHandler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "?prefix=user:")
+	assert.Nil(t, err)
+	defer response.Body.Close()
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	cache.Set("other", "ignored")
+	cache.SetWithTTL("user:1", map[string]int{"age": 42}, 10*time.Millisecond)
+	cache.Set("user:2", func() {})
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	next := func() (string, sseEvent) {
+		var name string
+		var event sseEvent
+		for line := range lines {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			case line == "":
+				return name, event
+			}
+		}
+		t.Fatal("Expected another event")
+		return "", event
+	}
+
+	name, event := next()
+	assert.Equal(t, "insert", name)
+	assert.Equal(t, "user:1", event.Key)
+	assert.Equal(t, map[string]interface{}{"age": float64(42)}, event.Value)
+	name, event = next()
+	assert.Equal(t, "insert", name)
+	assert.Equal(t, "user:2", event.Key)
+	assert.True(t, strings.HasPrefix(event.Value.(string), "0x"), "Expected a value which is no JSON to be formatted")
+	name, event = next()
+	assert.Equal(t, "expire", name)
+	assert.Equal(t, "user:1", event.Key)
+}
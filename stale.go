@@ -0,0 +1,103 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// staleValue is the value of an expired item kept for SetStaleIfError
+type staleValue struct {
+	data     interface{}
+	tags     []string
+	ttl      time.Duration
+	expireAt time.Time
+	until    time.Time
+}
+
+// staleKey is an entry of the order in which stale values are dropped
+type staleKey[K comparable] struct {
+	key   K
+	until time.Time
+}
+
+// SetStaleIfError keeps the values of expired items for the grace period, and GetOrLoad, and Get with
+// SetReadThrough, return such a stale value instead of the error when the load of the key fails. The data source can
+// be down for up to grace without callers noticing. Zero disables it and drops the kept values.
+func (cache *CacheOf[K]) SetStaleIfError(grace time.Duration) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.staleGrace = grace
+	if grace <= 0 {
+		cache.staleValues, cache.staleOrder = nil, nil
+	}
+}
+
+// keepStale keeps the value of an expired item for the grace period, the cache mutex must be held
+func (cache *CacheOf[K]) keepStale(item *ItemOf[K]) {
+	if cache.staleGrace <= 0 || item.TTL <= 0 {
+		return
+	}
+	now := cache.clock.Now()
+	cache.dropStale(now)
+	until := item.ExpireAt.Add(cache.staleGrace)
+	if !until.After(now) {
+		return
+	}
+	if cache.staleValues == nil {
+		cache.staleValues = make(map[K]staleValue)
+	}
+	cache.staleValues[item.key] = staleValue{data: item.Data, tags: item.tags, ttl: item.TTL, expireAt: item.ExpireAt,
+		until: until}
+	cache.staleOrder = append(cache.staleOrder, staleKey[K]{key: item.key, until: until})
+}
+
+// dropStale drops the stale values whose grace period is over, the cache mutex must be held.
+// Items expire roughly in order, so the oldest values are at the front.
+func (cache *CacheOf[K]) dropStale(now time.Time) {
+	dropped := 0
+	for _, entry := range cache.staleOrder {
+		if entry.until.After(now) {
+			break
+		}
+		if value, exists := cache.staleValues[entry.key]; exists && value.until.Equal(entry.until) {
+			delete(cache.staleValues, entry.key)
+		}
+		dropped++
+	}
+	cache.staleOrder = cache.staleOrder[dropped:]
+}
+
+// dropStaleFunc drops the stale values the predicate of a removal holds for, so removed values are not served once
+// the loader fails, the cache mutex must be held
+func (cache *CacheOf[K]) dropStaleFunc(predicate func(key K, item *ItemOf[K]) bool) {
+	for key, value := range cache.staleValues {
+		expired := &ItemOf[K]{key: key, Data: value.data, tags: value.tags, TTL: value.ttl, ExpireAt: value.expireAt,
+			clock: cache.clock}
+		if predicate(key, expired) {
+			delete(cache.staleValues, key)
+		}
+	}
+}
+
+// stale returns the stale value of the key, which is either still in the cache but expired, or was kept after its
+// expiration
+func (cache *CacheOf[K]) stale(key K) (interface{}, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.staleGrace <= 0 {
+		return nil, false
+	}
+	key = cache.normalize(key)
+	now := cache.clock.Now()
+	if item, exists := cache.items.Get(key); exists {
+		if item.expiredAt(now) && item.ExpireAt.Add(cache.staleGrace).After(now) {
+			return item.Data, true
+		}
+		return nil, false
+	}
+	cache.dropStale(now)
+	value, exists := cache.staleValues[key]
+	if !exists || !value.until.After(now) {
+		return nil, false
+	}
+	return value.data, true
+}
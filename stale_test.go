@@ -0,0 +1,71 @@
+package ttlcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SetStaleIfError(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	failing := errors.New("backend down")
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return nil, 0, failing
+	})
+	cache.SetStaleIfError(200 * time.Millisecond)
+	cache.SetWithTTL("key", "stale", 50*time.Millisecond)
+	cache.SetWithTTL("removed", "value", 50*time.Millisecond)
+	cache.Remove("removed")
+
+	<-time.After(100 * time.Millisecond)
+	assert.Equal(t, 0, cache.Count(), "Expected the item to expire")
+	data, err := cache.GetOrLoad(context.Background(), "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "stale", data, "Expected the stale value while the loader fails")
+	_, err = cache.GetOrLoad(context.Background(), "removed")
+	assert.Equal(t, failing, err, "Expected no stale value of removed items")
+
+	cache.SetReadThrough(true)
+	data, exists := cache.Get("key")
+	assert.True(t, exists)
+	assert.Equal(t, "stale", data)
+
+	<-time.After(200 * time.Millisecond)
+	_, err = cache.GetOrLoad(context.Background(), "key")
+	assert.Equal(t, failing, err, "Expected the error once the grace period is over")
+}
+
+func TestCache_SetStaleIfErrorRemoveExpired(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	failing := errors.New("backend down")
+	cache.SetLoader(func(ctx context.Context, key string) (interface{}, time.Duration, error) {
+		return nil, 0, failing
+	})
+	cache.SetStaleIfError(time.Hour)
+	for _, key := range []string{"removed", "popped", "tagged", "matched"} {
+		cache.SetWithTags(key, "old", 20*time.Millisecond, "tag-"+key)
+	}
+	<-time.After(50 * time.Millisecond)
+	cache.ProcessExpirations(time.Now())
+	data, err := cache.GetOrLoad(context.Background(), "removed")
+	assert.Nil(t, err)
+	assert.Equal(t, "old", data, "Expected the value kept after the expiration")
+
+	cache.Remove("removed")
+	cache.InvalidateTag("tag-tagged")
+	cache.RemoveByPrefix("match")
+	cache.SetWithTTL("popped", "new", time.Hour)
+	cache.Pop("popped")
+	for _, key := range []string{"removed", "popped", "tagged", "matched"} {
+		data, err = cache.GetOrLoad(context.Background(), key)
+		assert.Equal(t, failing, err, "Expected no stale value of %s after its removal", key)
+		assert.Nil(t, data)
+	}
+}
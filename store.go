@@ -0,0 +1,74 @@
+package ttlcache
+
+// Store is a StoreOf with string keys
+type Store = StoreOf[string]
+
+// StoreOf holds the items of a cache. The default is a plain map, SetStore plugs in other backends, such as sharded
+// maps or off-heap stores, without changing the cache logic. The cache calls a store with its mutex held, so a store
+// needs no locking of its own. Range must not be called recursively, and fn must not modify the store.
+type StoreOf[K comparable] interface {
+	// Get returns the item of the key
+	Get(key K) (*ItemOf[K], bool)
+	// Set stores the item under the key, replacing any previous item
+	Set(key K, item *ItemOf[K])
+	// Delete removes the key, it is a no-op for missing keys
+	Delete(key K)
+	// Len returns the number of stored items
+	Len() int
+	// Range calls fn for every item until fn returns false
+	Range(fn func(key K, item *ItemOf[K]) bool)
+}
+
+// mapStore is the default store
+type mapStore[K comparable] map[K]*ItemOf[K]
+
+func newMapStore[K comparable](size int) mapStore[K] {
+	return make(mapStore[K], size)
+}
+
+func (store mapStore[K]) Get(key K) (*ItemOf[K], bool) {
+	item, exists := store[key]
+	return item, exists
+}
+
+func (store mapStore[K]) Set(key K, item *ItemOf[K]) {
+	store[key] = item
+}
+
+func (store mapStore[K]) Delete(key K) {
+	delete(store, key)
+}
+
+func (store mapStore[K]) Len() int {
+	return len(store)
+}
+
+func (store mapStore[K]) Range(fn func(key K, item *ItemOf[K]) bool) {
+	for key, item := range store {
+		if !fn(key, item) {
+			return
+		}
+	}
+}
+
+// SetStore replaces the store of the items, the items of the current store are moved over
+func (cache *CacheOf[K]) SetStore(store StoreOf[K]) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.items.Range(func(key K, item *ItemOf[K]) bool {
+		store.Set(key, item)
+		return true
+	})
+	cache.items = store
+	cache.invalidateReads()
+}
+
+// storedItems returns all items of the store, for loops which remove items, the cache mutex must be held
+func (cache *CacheOf[K]) storedItems() []*ItemOf[K] {
+	items := make([]*ItemOf[K], 0, cache.items.Len())
+	cache.items.Range(func(_ K, item *ItemOf[K]) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
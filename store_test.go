@@ -0,0 +1,70 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingStore is a store which counts the calls the cache makes
+type countingStore struct {
+	items         map[string]*Item
+	sets, deletes int
+}
+
+func (store *countingStore) Get(key string) (*Item, bool) {
+	item, exists := store.items[key]
+	return item, exists
+}
+
+func (store *countingStore) Set(key string, item *Item) {
+	store.sets++
+	store.items[key] = item
+}
+
+func (store *countingStore) Delete(key string) {
+	store.deletes++
+	delete(store.items, key)
+}
+
+func (store *countingStore) Len() int {
+	return len(store.items)
+}
+
+func (store *countingStore) Range(fn func(key string, item *Item) bool) {
+	for key, item := range store.items {
+		if !fn(key, item) {
+			return
+		}
+	}
+}
+
+func TestCache_SetStore(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("a", 1)
+
+	store := &countingStore{items: make(map[string]*Item)}
+	cache.SetStore(store)
+	assert.Equal(t, 1, store.Len(), "Expected the items to move to the new store")
+
+	cache.SetWithTTL("b", 2, 50*time.Millisecond)
+	cache.Set("c", 3)
+	data, exists := cache.Get("a")
+	assert.True(t, exists)
+	assert.Equal(t, 1, data)
+	assert.Equal(t, 3, cache.Count())
+
+	assert.True(t, cache.Remove("c"))
+	<-time.After(100 * time.Millisecond)
+	_, exists = cache.Get("b")
+	assert.False(t, exists)
+	assert.Equal(t, 1, store.Len())
+	assert.Equal(t, 3, store.sets)
+	assert.Equal(t, 2, store.deletes)
+
+	cache.Purge()
+	assert.Equal(t, 0, store.Len())
+	assert.NoError(t, cache.VerifyIntegrity())
+}
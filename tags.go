@@ -0,0 +1,82 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// SetWithTags stores the item with an individual TTL and tags, such as the entities the value was computed from, so
+// InvalidateTag can remove all items carrying a tag at once. Other writes of the key clear the tags. It bypasses the
+// middleware, see Use.
+func (cache *CacheOf[K]) SetWithTags(key K, data interface{}, ttl time.Duration, tags ...string) {
+	cache.setWithOptions(key, data, ttl, itemOptions[K]{tags: tags})
+}
+
+// InvalidateTag removes all items carrying the tag and returns how many there were. The removal callback is called
+// for each of them.
+func (cache *CacheOf[K]) InvalidateTag(tag string) int {
+	cache.mutex.Lock()
+	removed := 0
+	for key := range cache.tags[tag] {
+		if item, exists := cache.items.Get(key); exists {
+			cache.deleteItem(item, EventRemoved)
+			removed++
+		}
+	}
+	cache.dropStaleFunc(func(_ K, item *ItemOf[K]) bool {
+		for _, itemTag := range item.tags {
+			if itemTag == tag {
+				return true
+			}
+		}
+		return false
+	})
+	cache.compactIfShrunk()
+	cache.mutex.Unlock()
+	if removed > 0 {
+		cache.notifyExpiration()
+	}
+	return removed
+}
+
+// GetTags returns the tags of the key
+func (cache *CacheOf[K]) GetTags(key K) ([]string, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		return nil, false
+	}
+	return append([]string(nil), item.tags...), true
+}
+
+// setTags replaces the tags of an item in the tag index, the cache mutex must be held
+func (cache *CacheOf[K]) setTags(item *ItemOf[K], tags []string) {
+	cache.untag(item)
+	if len(tags) == 0 {
+		return
+	}
+	item.tags = append([]string(nil), tags...)
+	if cache.tags == nil {
+		cache.tags = make(map[string]map[K]struct{})
+	}
+	for _, tag := range item.tags {
+		keys, exists := cache.tags[tag]
+		if !exists {
+			keys = make(map[K]struct{})
+			cache.tags[tag] = keys
+		}
+		keys[item.key] = struct{}{}
+	}
+}
+
+// untag removes an item from the tag index, the cache mutex must be held
+func (cache *CacheOf[K]) untag(item *ItemOf[K]) {
+	for _, tag := range item.tags {
+		keys := cache.tags[tag]
+		delete(keys, item.key)
+		if len(keys) == 0 {
+			delete(cache.tags, tag)
+		}
+	}
+	item.tags = nil
+}
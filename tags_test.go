@@ -0,0 +1,31 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_InvalidateTag(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTags("page:1", "a", time.Minute, "user:1", "product:7")
+	cache.SetWithTags("page:2", "b", time.Minute, "user:2", "product:7")
+	cache.SetWithTags("page:3", "c", time.Minute, "user:1")
+	cache.Set("page:4", "d")
+
+	tags, exists := cache.GetTags("page:1")
+	assert.True(t, exists)
+	assert.Equal(t, []string{"user:1", "product:7"}, tags)
+
+	assert.Equal(t, 2, cache.InvalidateTag("product:7"))
+	assert.False(t, cache.Has("page:1"))
+	assert.False(t, cache.Has("page:2"))
+	assert.Equal(t, 0, cache.InvalidateTag("product:7"))
+
+	cache.Set("page:3", "untagged")
+	assert.Equal(t, 0, cache.InvalidateTag("user:1"), "Expected a write without tags to clear them")
+	assert.Equal(t, 2, cache.Count())
+	assert.Empty(t, cache.tags, "Expected the tag index to be cleaned up")
+}
@@ -0,0 +1,223 @@
+package ttlcache
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrTenantLimit is returned when a write would take a Tenant over one of its limits
+var ErrTenantLimit = errors.New("ttlcache: tenant limit exceeded")
+
+// TenantLimits restrict how much of a Cache a Tenant may use, zero means no limit
+type TenantLimits struct {
+	// Entries is the maximum number of items
+	Entries int
+	// Cost is the maximum sum of the costs of the items, as returned by CostFunc
+	Cost int64
+	// CostFunc returns the cost of a value, such as its size in bytes. Without it every item costs 1. It is called with
+	// the cache locked, so it must not use the cache.
+	CostFunc func(value interface{}) int64
+}
+
+// TenantStats are the statistics of a Tenant. Items and Cost include expired items the cache did not remove yet.
+type TenantStats struct {
+	Metrics
+	Items int
+	Cost  int64
+}
+
+// Tenant is the part of a Cache that belongs to one customer of a multi-tenant service. Its keys are stored in the
+// Cache as Key(id, key), so they cannot collide with the keys of other tenants, and they are accounted separately:
+// writes which would exceed the limits of the tenant fail instead of pushing out the items of other tenants.
+// Items written to the Cache directly with a tenant key are accounted to the tenant as well, but not limited.
+type Tenant struct {
+	cache  *Cache
+	id     string
+	prefix string
+	limits TenantLimits
+	stats  TenantStats
+}
+
+// Tenant returns the tenant with the given id, every call with the same id returns the same Tenant. A key normalizer
+// must be set before, see SetKeyNormalizer.
+func (cache *Cache) Tenant(id string) *Tenant {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if tenant, exists := cache.tenants[id]; exists {
+		return tenant
+	}
+	if cache.tenants == nil {
+		cache.tenants = make(map[string]*Tenant)
+		cache.tenantPrefixes = make(map[string]*Tenant)
+		cache.observer = cache
+	}
+	tenant := &Tenant{cache: cache, id: id, prefix: Key(id) + keySeparator}
+	cache.tenants[id] = tenant
+	cache.tenantPrefixes[cache.normalize(tenant.prefix)] = tenant
+	tenant.recount()
+	return tenant
+}
+
+// PurgeTenant removes all items of the tenant with the given id and returns how many there were
+func (cache *Cache) PurgeTenant(id string) int {
+	return cache.Tenant(id).Purge()
+}
+
+// tenantOf returns the tenant a normalized key belongs to, the cache mutex must be held
+func (cache *Cache) tenantOf(key string) *Tenant {
+	end := strings.Index(key, keySeparator)
+	if end < 0 {
+		return nil
+	}
+	return cache.tenantPrefixes[key[:end+len(keySeparator)]]
+}
+
+func (cache *Cache) itemAdded(item *Item) {
+	if tenant := cache.tenantOf(item.key); tenant != nil {
+		tenant.stats.Items++
+		tenant.stats.Cost += tenant.costOfItem(item)
+		tenant.stats.Inserted++
+	}
+}
+
+func (cache *Cache) itemUpdated(item *Item, oldData interface{}, oldWeight int64) {
+	if tenant := cache.tenantOf(item.key); tenant != nil {
+		tenant.stats.Cost += tenant.costOfItem(item) - tenant.cost(oldData, oldWeight)
+	}
+}
+
+func (cache *Cache) itemRemoved(item *Item, evicted bool) {
+	if tenant := cache.tenantOf(item.key); tenant != nil {
+		tenant.stats.Items--
+		tenant.stats.Cost -= tenant.costOfItem(item)
+		if evicted {
+			tenant.stats.Evicted++
+		}
+	}
+}
+
+// ID returns the id of the tenant
+func (tenant *Tenant) ID() string {
+	return tenant.id
+}
+
+// SetLimits sets the limits of the tenant. Items already stored are kept even when they exceed the new limits,
+// the costs of the items are recalculated with the new CostFunc.
+func (tenant *Tenant) SetLimits(limits TenantLimits) {
+	tenant.cache.mutex.Lock()
+	defer tenant.cache.mutex.Unlock()
+	tenant.limits = limits
+	tenant.recount()
+}
+
+// recount recalculates the items and cost of the tenant, the cache mutex must be held
+func (tenant *Tenant) recount() {
+	tenant.stats.Items, tenant.stats.Cost = 0, 0
+	prefix := tenant.cache.normalize(tenant.prefix)
+	tenant.cache.items.Range(func(key string, item *Item) bool {
+		if strings.HasPrefix(key, prefix) {
+			tenant.stats.Items++
+			tenant.stats.Cost += tenant.costOfItem(item)
+		}
+		return true
+	})
+}
+
+func (tenant *Tenant) costOfItem(item *Item) int64 {
+	return tenant.cost(item.Data, item.weight)
+}
+
+// cost prefers the weight of a value, reported with it or computed by the weigher of the cache, over the CostFunc
+func (tenant *Tenant) cost(value interface{}, weight int64) int64 {
+	if weight > 0 {
+		return weight
+	}
+	if tenant.limits.CostFunc == nil {
+		return 1
+	}
+	return tenant.limits.CostFunc(value)
+}
+
+// key returns the key of the Cache for a key of the tenant
+func (tenant *Tenant) key(key string) string {
+	return tenant.prefix + keyEscaper.Replace(key)
+}
+
+// Set adds the item with the global TTL, unless it would exceed the limits of the tenant
+func (tenant *Tenant) Set(key string, data interface{}) error {
+	return tenant.SetWithTTL(key, data, ItemExpireWithGlobalTTL)
+}
+
+// SetWithTTL adds the item with an individual TTL, unless it would exceed the limits of the tenant. The limits are
+// checked and the item is stored under one lock of the cache, so concurrent writes cannot pass the check together,
+// which is why the writes of a tenant bypass the middleware, see Use.
+func (tenant *Tenant) SetWithTTL(key string, data interface{}, ttl time.Duration) error {
+	cache := tenant.cache
+	key = tenant.key(key)
+	data = cache.lockForSet(data)
+	if cache.isShutDown {
+		cache.mutex.Unlock()
+		return ErrCacheClosed
+	}
+	normalized := cache.normalize(key)
+	var weight int64
+	if cache.weigher != nil {
+		weight = cache.weigher(normalized, data)
+	}
+	items, cost := tenant.stats.Items, tenant.stats.Cost+tenant.cost(data, weight)
+	if item, exists := cache.items.Get(normalized); exists {
+		cost -= tenant.costOfItem(item)
+	} else {
+		items++
+	}
+	limits := tenant.limits
+	if (limits.Entries > 0 && items > limits.Entries) || (limits.Cost > 0 && cost > limits.Cost) {
+		cache.mutex.Unlock()
+		return ErrTenantLimit
+	}
+	key, isNew := cache.set(key, data, ttl, itemOptions[string]{})
+	newItemCallback := cache.newItemCallback
+	cache.mutex.Unlock()
+	if isNew && newItemCallback != nil {
+		newItemCallback(key, data)
+	}
+	cache.notifyExpiration()
+	return nil
+}
+
+// Get looks up an item of the tenant
+func (tenant *Tenant) Get(key string) (interface{}, bool) {
+	data, exists := tenant.cache.Get(tenant.key(key))
+	tenant.cache.mutex.Lock()
+	tenant.stats.Retrievals++
+	if exists {
+		tenant.stats.Hits++
+	} else {
+		tenant.stats.Misses++
+	}
+	tenant.cache.mutex.Unlock()
+	return data, exists
+}
+
+// Remove removes an item of the tenant
+func (tenant *Tenant) Remove(key string) bool {
+	return tenant.cache.Remove(tenant.key(key))
+}
+
+// Stats returns a snapshot of the statistics of the tenant
+func (tenant *Tenant) Stats() TenantStats {
+	tenant.cache.mutex.Lock()
+	defer tenant.cache.mutex.Unlock()
+	return tenant.stats
+}
+
+// Purge removes all items of the tenant and returns how many there were
+func (tenant *Tenant) Purge() int {
+	tenant.cache.mutex.Lock()
+	defer tenant.cache.mutex.Unlock()
+	prefix := tenant.cache.normalize(tenant.prefix)
+	return tenant.cache.removeFunc(func(key string, item *Item) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
@@ -0,0 +1,88 @@
+package ttlcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Tenant(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+
+	acme, globex := cache.Tenant("acme"), cache.Tenant("globex")
+	assert.True(t, acme == cache.Tenant("acme"))
+	acme.SetLimits(TenantLimits{Entries: 2, Cost: 10, CostFunc: func(value interface{}) int64 {
+		return int64(len(value.(string)))
+	}})
+
+	assert.Nil(t, acme.Set("a", "12345"))
+	assert.Nil(t, acme.SetWithTTL("b", "1234", 10*time.Millisecond))
+	assert.Equal(t, ErrTenantLimit, acme.Set("c", "1"), "Expected the entry limit to be enforced")
+	assert.Equal(t, ErrTenantLimit, acme.Set("a", "1234567"), "Expected the cost limit to be enforced")
+	assert.Nil(t, acme.Set("a", "123456"))
+	assert.Nil(t, globex.Set("a", "other tenant"))
+
+	data, _ := acme.Get("a")
+	assert.Equal(t, "123456", data)
+	acme.Get("missing")
+	stats := acme.Stats()
+	assert.Equal(t, 2, stats.Items)
+	assert.Equal(t, int64(10), stats.Cost)
+	assert.Equal(t, int64(2), stats.Inserted)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+
+	<-time.After(50 * time.Millisecond)
+	stats = acme.Stats()
+	assert.Equal(t, 1, stats.Items, "Expected the expired item to leave the tenant")
+	assert.Equal(t, int64(1), stats.Evicted)
+
+	assert.Equal(t, 1, cache.PurgeTenant("acme"))
+	assert.Equal(t, 0, acme.Stats().Items)
+	data, _ = globex.Get("a")
+	assert.Equal(t, "other tenant", data)
+}
+
+func TestCache_TenantWithKeyNormalizer(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetKeyNormalizer(FoldKeyCase)
+
+	tenant := cache.Tenant("Acme")
+	tenant.SetLimits(TenantLimits{Entries: 1})
+	assert.Nil(t, tenant.Set("Page", "body"))
+	assert.Equal(t, 1, tenant.Stats().Items, "Expected the normalized key to be accounted to the tenant")
+	assert.Equal(t, ErrTenantLimit, tenant.Set("other", "body"))
+	assert.True(t, tenant.Remove("PAGE"))
+	assert.Equal(t, 0, tenant.Stats().Items)
+}
+
+func TestCache_TenantConcurrentWritesWithWeigher(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWeigher(func(key string, value interface{}) int64 {
+		return int64(len(value.(string)))
+	}, 0)
+
+	tenant := cache.Tenant("acme")
+	// the weigher of the cache wins over the CostFunc, for the check just like for the accounting
+	tenant.SetLimits(TenantLimits{Cost: 10, CostFunc: func(value interface{}) int64 {
+		return 1
+	}})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tenant.Set(strconv.Itoa(i), "abcd")
+		}(i)
+	}
+	wg.Wait()
+	stats := tenant.Stats()
+	assert.Equal(t, 2, stats.Items, "Expected concurrent writes not to pass the limit together")
+	assert.Equal(t, int64(8), stats.Cost)
+}
@@ -0,0 +1,157 @@
+package ttlcache
+
+import (
+	"time"
+)
+
+// TTLFunc computes the TTL of an item from its key and value, see SetTTLFunc
+type TTLFunc[K comparable] func(key K, value interface{}) time.Duration
+
+// SetTTLFunc makes the cache compute the TTL of items written without an individual TTL from their content, for
+// instance from an Expires header kept with the value. When the function returns zero the global TTL applies. It is
+// called with the cache locked, so it must not use the cache.
+func (cache *CacheOf[K]) SetTTLFunc(ttlFunc TTLFunc[K]) {
+	cache.mutex.Lock()
+	cache.ttlFunc = ttlFunc
+	cache.mutex.Unlock()
+}
+
+// SetTTLWithRescale sets the global TTL like SetTTL, and scales the remaining lifetime of the items which were written
+// without an individual TTL by the ratio of the new TTL to the one they have, so a shorter TTL takes effect right away
+// instead of once the items are written again. Halving the TTL halves the time the items have left. Items with an
+// individual TTL are left alone, even when it equals the global one, as are all items when the new TTL is not
+// positive.
+func (cache *CacheOf[K]) SetTTLWithRescale(ttl time.Duration) {
+	cache.mutex.Lock()
+	cache.ttl = ttl
+	cache.invalidateReads()
+	if ttl > 0 {
+		now := cache.clock.Now()
+		cache.priorityQueue.startBatch()
+		cache.items.Range(func(key K, item *ItemOf[K]) bool {
+			if !item.globalTTL || item.TTL <= 0 || item.TTL == ttl || item.expiredAt(now) {
+				return true
+			}
+			old := item.TTL
+			scale := float64(ttl) / float64(old)
+			item.TTL = ttl
+			item.ExpireAt = now.Add(time.Duration(float64(item.ExpireAt.Sub(now)) * scale))
+			item.writeExpireAt = item.writeExpireAt.Add(ttl - old)
+			item.capLifetime()
+			return true
+		})
+		cache.priorityQueue.endBatch()
+	}
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+}
+
+// GetAndRefresh looks up an item like Get and gives it the new TTL, starting now, in the same operation. Unlike Get
+// followed by SetWithTTL it neither stores the value again nor counts as a write.
+func (cache *CacheOf[K]) GetAndRefresh(key K, ttl time.Duration) (interface{}, bool) {
+	cache.mutex.Lock()
+	cache.metrics.Retrievals++
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		cache.metrics.Misses++
+		cache.mutex.Unlock()
+		return nil, false
+	}
+	item.hits++
+	cache.metrics.Hits++
+	cache.retime(item, ttl)
+	data, copier := item.Data, cache.valueCopier
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+	if copier != nil {
+		data = copier(data)
+	}
+	return data, true
+}
+
+// Touch restarts the TTL of the item from now, without reading or writing its value or counting in the metrics,
+// and reports whether the key exists. It works regardless of SkipTtlExtensionOnHit.
+func (cache *CacheOf[K]) Touch(key K) bool {
+	cache.mutex.Lock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		cache.mutex.Unlock()
+		return false
+	}
+	cache.retime(item, item.TTL)
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+	return true
+}
+
+// ExtendTTL adds d to the remaining lifetime of the item, instead of restarting its TTL from now, for lease renewals.
+// It reports whether the key exists, items which do not expire are left alone. The TTL of later hits is unchanged, and
+// the item does not outlive its maximum lifetime, see SetMaxLifetime.
+func (cache *CacheOf[K]) ExtendTTL(key K, d time.Duration) bool {
+	cache.mutex.Lock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		cache.mutex.Unlock()
+		return false
+	}
+	if !item.ExpireAt.IsZero() && item.TTL > 0 {
+		cache.invalidateReads()
+		item.ExpireAt = item.ExpireAt.Add(d)
+		item.writeExpireAt = item.writeExpireAt.Add(d)
+		item.capLifetime()
+		cache.priorityQueue.update(item)
+	}
+	cache.mutex.Unlock()
+	cache.notifyExpiration()
+	return true
+}
+
+// SetWithExpireAt stores the item until the given point in time, such as the expiry of a token, instead of for a
+// duration. Hits do not extend such items. A point in time which has passed removes the key instead.
+func (cache *CacheOf[K]) SetWithExpireAt(key K, data interface{}, at time.Time) {
+	cache.SetWithOptions(key, data, WithExpireAt(at))
+}
+
+// GetRemainingTTL returns how long the item lives unless it is touched again, unlike GetTTL which returns the
+// lifetime it gets on every touch, for instance for the max-age of Cache-Control. It is ItemNotExpire for items that
+// do not expire. The item is not touched.
+func (cache *CacheOf[K]) GetRemainingTTL(key K) (time.Duration, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	item, exists := cache.items.Get(cache.normalize(key))
+	if !exists || item.expired() {
+		return 0, false
+	}
+	if !item.expires() {
+		return ItemNotExpire, true
+	}
+	return item.ExpireAt.Sub(cache.clock.Now()), true
+}
+
+// GetExpireAt returns when the item expires unless it is touched again, the time is zero for items that do not
+// expire. The item is not touched.
+func (cache *CacheOf[K]) GetExpireAt(key K) (time.Time, bool) {
+	_, expireAt, exists := cache.peek(key)
+	return expireAt, exists
+}
+
+// retime gives an item a new TTL starting now, like a write of the item would, the cache mutex must be held
+func (cache *CacheOf[K]) retime(item *ItemOf[K], ttl time.Duration) {
+	if cache.expirationDisabled {
+		ttl = ItemNotExpire
+	}
+	item.TTL = ttl
+	item.globalTTL = item.TTL == 0
+	if item.TTL >= 0 && (item.TTL > 0 || cache.ttl > 0) {
+		if cache.ttl > 0 && item.TTL == 0 {
+			item.TTL = cache.ttl
+		}
+		cache.touch(item)
+	} else {
+		cache.invalidateReads()
+		item.ExpireAt = time.Time{}
+	}
+	item.capLifetime()
+	item.writeExpireAt = item.ExpireAt
+	cache.priorityQueue.update(item)
+}
@@ -0,0 +1,168 @@
+package ttlcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetAndRefresh(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("key", "value", 50*time.Millisecond)
+	meta, _ := cache.GetItemMeta("key")
+
+	data, exists := cache.GetAndRefresh("key", 200*time.Millisecond)
+	assert.True(t, exists)
+	assert.Equal(t, "value", data)
+	ttl, _ := cache.GetTTL("key")
+	assert.Equal(t, 200*time.Millisecond, ttl)
+	after, _ := cache.GetItemMeta("key")
+	assert.Equal(t, meta.Version, after.Version, "Expected the value not to be written again")
+
+	<-time.After(100 * time.Millisecond)
+	assert.True(t, cache.Has("key"), "Expected the new TTL to apply")
+
+	_, exists = cache.GetAndRefresh("key", ItemNotExpire)
+	assert.True(t, exists)
+	meta, _ = cache.GetItemMeta("key")
+	assert.True(t, meta.ExpireAt.IsZero())
+	_, exists = cache.GetAndRefresh("missing", time.Minute)
+	assert.False(t, exists)
+	assert.NoError(t, cache.VerifyIntegrity())
+}
+
+func TestCache_Touch(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SkipTtlExtensionOnHit(true)
+	cache.SetWithTTL("key", "value", 100*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		<-time.After(50 * time.Millisecond)
+		assert.True(t, cache.Touch("key"))
+	}
+	assert.True(t, cache.Has("key"), "Expected Touch to keep the item alive")
+	assert.Equal(t, int64(0), cache.GetMetrics().Retrievals)
+
+	<-time.After(150 * time.Millisecond)
+	assert.False(t, cache.Touch("key"))
+	assert.NoError(t, cache.VerifyIntegrity())
+}
+
+func TestCache_ExtendTTL(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetWithTTL("lease", "owner", time.Minute)
+	cache.SetWithTTL("forever", "value", ItemNotExpire)
+	before, _ := cache.GetItemMeta("lease")
+
+	assert.True(t, cache.ExtendTTL("lease", 30*time.Second))
+	after, _ := cache.GetItemMeta("lease")
+	assert.Equal(t, before.ExpireAt.Add(30*time.Second), after.ExpireAt, "Expected the remaining lifetime to grow")
+	assert.Equal(t, time.Minute, after.TTL)
+	assert.NoError(t, cache.VerifyIntegrity())
+
+	assert.True(t, cache.ExtendTTL("lease", -2*time.Minute))
+	assert.False(t, cache.Has("lease"), "Expected a negative delta to shorten the lease")
+	assert.True(t, cache.ExtendTTL("forever", time.Second))
+	assert.False(t, cache.ExtendTTL("missing", time.Second))
+}
+
+func TestCache_SetWithExpireAt(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetTTL(time.Minute)
+	at := time.Now().Add(time.Hour)
+	cache.SetWithExpireAt("token", "secret", at)
+
+	meta, exists := cache.GetItemMeta("token")
+	assert.True(t, exists)
+	assert.True(t, at.Equal(meta.ExpireAt), "Expected the item to expire at the given time")
+	_, _ = cache.Get("token")
+	meta, _ = cache.GetItemMeta("token")
+	assert.True(t, at.Equal(meta.ExpireAt), "Expected a hit not to extend the item")
+
+	cache.Set("token", "renewed")
+	meta, _ = cache.GetItemMeta("token")
+	assert.False(t, at.Equal(meta.ExpireAt), "Expected a plain write to restore the global TTL")
+
+	cache.SetWithExpireAt("token", "stale", time.Now().Add(-time.Second))
+	assert.False(t, cache.Has("token"), "Expected a past time to remove the key")
+	assert.NoError(t, cache.VerifyIntegrity())
+}
+
+func TestCache_GetRemainingTTL(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	at := time.Now().Add(time.Hour)
+	cache.SetWithExpireAt("token", "secret", at)
+	cache.SetWithTTL("forever", "value", ItemNotExpire)
+
+	remaining, exists := cache.GetRemainingTTL("token")
+	assert.True(t, exists)
+	assert.True(t, remaining > 59*time.Minute && remaining <= time.Hour, "Expected the time left, got %v", remaining)
+	expireAt, exists := cache.GetExpireAt("token")
+	assert.True(t, exists)
+	assert.True(t, at.Equal(expireAt))
+
+	remaining, exists = cache.GetRemainingTTL("forever")
+	assert.True(t, exists)
+	assert.Equal(t, ItemNotExpire, remaining)
+	expireAt, exists = cache.GetExpireAt("forever")
+	assert.True(t, exists)
+	assert.True(t, expireAt.IsZero())
+
+	_, exists = cache.GetRemainingTTL("missing")
+	assert.False(t, exists)
+	_, exists = cache.GetExpireAt("missing")
+	assert.False(t, exists)
+}
+
+func TestCache_SetTTLFunc(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetTTL(time.Hour)
+	cache.SetTTLFunc(func(key string, value interface{}) time.Duration {
+		if response, ok := value.(map[string]time.Duration); ok {
+			return response["max-age"]
+		}
+		return 0
+	})
+	cache.Set("response", map[string]time.Duration{"max-age": time.Minute})
+	cache.Set("plain", "value")
+	cache.SetWithTTL("explicit", map[string]time.Duration{"max-age": time.Minute}, 2*time.Minute)
+
+	ttl, _ := cache.GetTTL("response")
+	assert.Equal(t, time.Minute, ttl, "Expected the TTL computed from the value")
+	ttl, _ = cache.GetTTL("plain")
+	assert.Equal(t, time.Hour, ttl, "Expected the global TTL when the function returns zero")
+	ttl, _ = cache.GetTTL("explicit")
+	assert.Equal(t, 2*time.Minute, ttl, "Expected an individual TTL to take precedence")
+}
+
+func TestCache_SetTTLWithRescale(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetTTL(time.Hour)
+	cache.Set("global", "value")
+	cache.SetWithTTL("individual", "value", 2*time.Hour)
+	cache.SetWithTTL("same", "value", time.Hour)
+	before, _ := cache.GetRemainingTTL("global")
+
+	cache.SetTTLWithRescale(time.Hour / 4)
+	remaining, _ := cache.GetRemainingTTL("global")
+	assert.InDelta(t, float64(before/4), float64(remaining), float64(time.Second), "Expected a quarter of the time left")
+	ttl, _ := cache.GetTTL("global")
+	assert.Equal(t, time.Hour/4, ttl)
+	ttl, _ = cache.GetTTL("individual")
+	assert.Equal(t, 2*time.Hour, ttl, "Expected an individual TTL to be left alone")
+	remaining, _ = cache.GetRemainingTTL("individual")
+	assert.True(t, remaining > time.Hour, "Expected an individual TTL to be left alone")
+	ttl, _ = cache.GetTTL("same")
+	assert.Equal(t, time.Hour, ttl, "Expected an individual TTL equal to the old global one to be left alone")
+	remaining, _ = cache.GetRemainingTTL("same")
+	assert.True(t, remaining > 59*time.Minute, "Expected an individual TTL equal to the old global one to be left alone")
+	assert.NoError(t, cache.VerifyIntegrity())
+}
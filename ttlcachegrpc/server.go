@@ -0,0 +1,128 @@
+// Package ttlcachegrpc serves a ttlcache.Cache over gRPC, so other services or sidecars can share the cache of
+// a Go process. The service is defined in ttlcachepb/cache.proto, values are opaque bytes.
+//
+//	server := grpc.NewServer()
+//	ttlcachepb.RegisterCacheServer(server, ttlcachegrpc.NewServer(cache))
+//	server.Serve(listener)
+//
+// It is a separate module so the gRPC dependency is only pulled in when it is used.
+package ttlcachegrpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jadevelopmentgrp/TTLCache"
+	"github.com/jadevelopmentgrp/TTLCache/ttlcachegrpc/ttlcachepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchBuffer is the number of events buffered per Watch stream, a slower client misses events
+const watchBuffer = 256
+
+// Server implements ttlcachepb.CacheServer on top of a Cache
+type Server struct {
+	ttlcachepb.UnimplementedCacheServer
+	cache *ttlcache.Cache
+}
+
+// NewServer binds a Server to the cache
+func NewServer(cache *ttlcache.Cache) *Server {
+	return &Server{cache: cache}
+}
+
+// Get returns the value of a key. Values set from Go as a string are returned as bytes,
+// any other type than []byte or string results in a FailedPrecondition error.
+func (s *Server) Get(ctx context.Context, request *ttlcachepb.GetRequest) (*ttlcachepb.GetResponse, error) {
+	data, exists := s.cache.Get(request.GetKey())
+	if !exists {
+		return &ttlcachepb.GetResponse{}, nil
+	}
+	value, ok := toBytes(data)
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "value of %q is a %T, not bytes", request.GetKey(), data)
+	}
+	return &ttlcachepb.GetResponse{Found: true, Value: value}, nil
+}
+
+// Set stores the value with the requested TTL, or the global TTL when the request has none
+func (s *Server) Set(ctx context.Context, request *ttlcachepb.SetRequest) (*ttlcachepb.SetResponse, error) {
+	ttl := ttlcache.ItemExpireWithGlobalTTL
+	if request.GetTtl() != nil {
+		if err := request.GetTtl().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		ttl = request.GetTtl().AsDuration()
+		if ttl < 0 {
+			ttl = ttlcache.ItemNotExpire
+		}
+	}
+	s.cache.SetWithTTL(request.GetKey(), request.GetValue(), ttl)
+	return &ttlcachepb.SetResponse{}, nil
+}
+
+// Delete removes a key
+func (s *Server) Delete(ctx context.Context, request *ttlcachepb.DeleteRequest) (*ttlcachepb.DeleteResponse, error) {
+	return &ttlcachepb.DeleteResponse{Found: s.cache.Remove(request.GetKey())}, nil
+}
+
+// Stats returns the metrics of the cache
+func (s *Server) Stats(ctx context.Context, request *ttlcachepb.StatsRequest) (*ttlcachepb.StatsResponse, error) {
+	metrics := s.cache.GetMetrics()
+	return &ttlcachepb.StatsResponse{
+		Count:      int64(s.cache.Count()),
+		Inserted:   metrics.Inserted,
+		Retrievals: metrics.Retrievals,
+		Hits:       metrics.Hits,
+		Misses:     metrics.Misses,
+		Evicted:    metrics.Evicted,
+	}, nil
+}
+
+// Watch streams the events of keys with the requested prefix until the client goes away or the cache is closed.
+// The response headers are sent once the subscription is in place, clients waiting for them miss no events.
+func (s *Server) Watch(request *ttlcachepb.WatchRequest, stream ttlcachepb.Cache_WatchServer) error {
+	subscription := s.cache.Subscribe(watchBuffer)
+	defer subscription.Close()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, open := <-subscription.C:
+			if !open {
+				return status.Error(codes.Unavailable, "cache closed")
+			}
+			if !strings.HasPrefix(event.Key, request.GetKeyPrefix()) {
+				continue
+			}
+			value, _ := toBytes(event.Value)
+			err := stream.Send(&ttlcachepb.Event{
+				Type:  ttlcachepb.Event_Type(event.Type),
+				Key:   event.Key,
+				Value: value,
+				Time:  timestamppb.New(event.Time),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toBytes(data interface{}) ([]byte, bool) {
+	switch value := data.(type) {
+	case []byte:
+		return value, true
+	case string:
+		return []byte(value), true
+	default:
+		return nil, false
+	}
+}
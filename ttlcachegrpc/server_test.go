@@ -0,0 +1,84 @@
+package ttlcachegrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jadevelopmentgrp/TTLCache"
+	"github.com/jadevelopmentgrp/TTLCache/ttlcachegrpc/ttlcachepb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestServer(t *testing.T) {
+	cache := ttlcache.NewCache()
+	defer cache.Close()
+
+	listener := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	ttlcachepb.RegisterCacheServer(server, NewServer(cache))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+	client := ttlcachepb.NewCacheClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	watch, err := client.Watch(ctx, &ttlcachepb.WatchRequest{KeyPrefix: "user:"})
+	assert.Nil(t, err)
+	_, err = watch.Header()
+	assert.Nil(t, err)
+
+	_, err = client.Set(ctx, &ttlcachepb.SetRequest{Key: "user:1", Value: []byte("alice"), Ttl: durationpb.New(time.Minute)})
+	assert.Nil(t, err)
+	got, err := client.Get(ctx, &ttlcachepb.GetRequest{Key: "user:1"})
+	assert.Nil(t, err)
+	assert.True(t, got.GetFound())
+	assert.Equal(t, []byte("alice"), got.GetValue())
+	ttl, _ := cache.GetTTL("user:1")
+	assert.Equal(t, time.Minute, ttl)
+
+	cache.Set("struct", struct{}{})
+	_, err = client.Get(ctx, &ttlcachepb.GetRequest{Key: "struct"})
+	assert.NotNil(t, err)
+
+	deleted, err := client.Delete(ctx, &ttlcachepb.DeleteRequest{Key: "user:1"})
+	assert.Nil(t, err)
+	assert.True(t, deleted.GetFound())
+	got, _ = client.Get(ctx, &ttlcachepb.GetRequest{Key: "user:1"})
+	assert.False(t, got.GetFound())
+
+	stats, err := client.Stats(ctx, &ttlcachepb.StatsRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), stats.GetCount())
+
+	event, err := watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, ttlcachepb.Event_INSERTED, event.GetType())
+	assert.Equal(t, "user:1", event.GetKey())
+	assert.Equal(t, []byte("alice"), event.GetValue())
+	event, err = watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, ttlcachepb.Event_REMOVED, event.GetType())
+
+	cache.Set("user:2", "bob")
+	cache.Purge()
+	event, err = watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, ttlcachepb.Event_INSERTED, event.GetType())
+	event, err = watch.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, ttlcachepb.Event_PURGED, event.GetType())
+	assert.Equal(t, "PURGED", event.GetType().String(), "Expected the descriptor to know every event type")
+	assert.Equal(t, ttlcachepb.Event_PURGED, ttlcachepb.Event_Type(ttlcache.EventPurged))
+}
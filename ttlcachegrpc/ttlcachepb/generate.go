@@ -0,0 +1,4 @@
+// Package ttlcachepb contains the protocol buffer definition of the cache service and the generated Go bindings.
+package ttlcachepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cache.proto
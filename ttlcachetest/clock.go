@@ -0,0 +1,127 @@
+// Package ttlcachetest helps to test code which uses a ttlcache without sleeping. Its FakeClock only moves when the
+// test advances it, and Settle waits until the expirations due at the current time are processed.
+//
+//	clock := ttlcachetest.NewFakeClock(time.Now())
+//	cache := ttlcache.NewCache()
+//	cache.SetClock(clock)
+//	cache.SetWithTTL("session", session, time.Minute)
+//	clock.Advance(2 * time.Minute)
+//	ttlcachetest.Settle(cache, clock)
+package ttlcachetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jadevelopmentgrp/TTLCache"
+)
+
+// FakeClock is a ttlcache.Clock whose time only changes with Advance and Set
+type FakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers map[*fakeTimer]struct{}
+}
+
+// NewFakeClock creates a FakeClock showing the given time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, timers: make(map[*fakeTimer]struct{})}
+}
+
+// Now returns the time of the clock
+func (clock *FakeClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+// NewTimer returns a timer which fires once the clock is advanced by the duration
+func (clock *FakeClock) NewTimer(d time.Duration) ttlcache.Timer {
+	timer := &fakeTimer{clock: clock, c: make(chan time.Time, 1)}
+	timer.Reset(d)
+	return timer
+}
+
+// Advance moves the clock forward and fires the timers which are due
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.setLocked(clock.now.Add(d))
+}
+
+// Set moves the clock to the given time and fires the timers which are due
+func (clock *FakeClock) Set(now time.Time) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.setLocked(now)
+}
+
+// Timers returns the number of timers which did not fire yet, a cache has one while it waits for the next expiration
+func (clock *FakeClock) Timers() int {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return len(clock.timers)
+}
+
+func (clock *FakeClock) setLocked(now time.Time) {
+	clock.now = now
+	for timer := range clock.timers {
+		if !timer.deadline.After(now) {
+			timer.fire(now)
+		}
+	}
+}
+
+// fakeTimer is a timer of a FakeClock, it is pending while it is in the timers of its clock
+type fakeTimer struct {
+	clock    *FakeClock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (timer *fakeTimer) C() <-chan time.Time {
+	return timer.c
+}
+
+func (timer *fakeTimer) Reset(d time.Duration) bool {
+	timer.clock.mutex.Lock()
+	defer timer.clock.mutex.Unlock()
+	_, pending := timer.clock.timers[timer]
+	timer.deadline = timer.clock.now.Add(d)
+	if d <= 0 {
+		timer.fire(timer.clock.now)
+	} else {
+		timer.clock.timers[timer] = struct{}{}
+	}
+	return pending
+}
+
+func (timer *fakeTimer) Stop() bool {
+	timer.clock.mutex.Lock()
+	defer timer.clock.mutex.Unlock()
+	_, pending := timer.clock.timers[timer]
+	delete(timer.clock.timers, timer)
+	return pending
+}
+
+// fire sends the time like an expired time.Timer, the clock mutex must be held
+func (timer *fakeTimer) fire(now time.Time) {
+	delete(timer.clock.timers, timer)
+	select {
+	case timer.c <- now:
+	default:
+	}
+}
+
+// Expirer is the part of a cache Settle uses, *ttlcache.Cache and *ttlcache.CacheOf implement it
+type Expirer interface {
+	ProcessExpirations(now time.Time)
+	Drain()
+}
+
+// Settle removes the items which are expired at the time of the clock and waits until the callbacks of those
+// removals returned, so a test can assert on them right after Advance.
+func Settle(cache Expirer, clock *FakeClock) {
+	cache.ProcessExpirations(clock.Now())
+	cache.Drain()
+}
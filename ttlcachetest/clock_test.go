@@ -0,0 +1,68 @@
+package ttlcachetest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jadevelopmentgrp/TTLCache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock_Timer(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Minute)
+	assert.Equal(t, 1, clock.Timers())
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("Expected the timer to wait for the full minute")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-timer.C())
+	assert.Equal(t, 0, clock.Timers())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	clock.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatal("Expected a stopped timer not to fire")
+	default:
+	}
+}
+
+func TestSettle(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := ttlcache.NewCache()
+	defer cache.Close()
+	cache.SetClock(clock)
+
+	var mutex sync.Mutex
+	var expired []string
+	cache.SetExpirationCallback(func(key string, value interface{}) {
+		mutex.Lock()
+		expired = append(expired, key)
+		mutex.Unlock()
+	})
+	cache.SetWithTTL("short", 1, time.Minute)
+	cache.SetWithTTL("long", 2, time.Hour)
+
+	clock.Advance(59 * time.Second)
+	Settle(cache, clock)
+	assert.Equal(t, 2, cache.Count())
+
+	clock.Advance(2 * time.Second)
+	Settle(cache, clock)
+	_, exists := cache.Get("short")
+	assert.False(t, exists)
+	_, exists = cache.Get("long")
+	assert.True(t, exists)
+	mutex.Lock()
+	assert.Equal(t, []string{"short"}, expired)
+	mutex.Unlock()
+}
@@ -0,0 +1,127 @@
+package ttlcache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrConflict is returned by Txn when a key the transaction read was written by someone else before it committed
+var ErrConflict = errors.New("ttlcache: transaction conflict")
+
+// Tx is a TxOf a Cache
+type Tx = TxOf[string]
+
+// TxOf collects the writes of a transaction started with Txn. Lookups see the writes of the transaction itself and
+// otherwise the current content of the cache.
+type TxOf[K comparable] struct {
+	cache *CacheOf[K]
+	// writes, order and reads are keyed by the normalized key, so spellings of one key are the same key
+	writes map[K]childWrite
+	order  []K
+	reads  map[K]uint64
+}
+
+// Txn runs fn and, when it returns nil, applies all writes fn made through the Tx at once: other goroutines see either
+// none or all of them. When fn returns an error the writes are discarded and the error is returned, on a closed cache
+// the writes are discarded as well and ErrCacheClosed is returned. The middleware does not see the writes, see Use.
+// Txn is optimistic: the version of every key read through the Tx is recorded, and when one of them changed before
+// the commit, the writes are discarded and ErrConflict is returned, so the caller can run the transaction again.
+func (cache *CacheOf[K]) Txn(fn func(tx *TxOf[K]) error) error {
+	tx := &TxOf[K]{cache: cache, writes: make(map[K]childWrite), reads: make(map[K]uint64)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit()
+}
+
+// Get returns the value written by the transaction, or looks the key up in the cache and records its version
+func (tx *TxOf[K]) Get(key K) (interface{}, bool) {
+	key = tx.cache.normalizeKey(key)
+	if write, exists := tx.writes[key]; exists {
+		return write.data, !write.removed
+	}
+	if _, read := tx.reads[key]; !read {
+		// taken before the lookup, a write in between makes the commit fail instead of going unnoticed
+		tx.cache.mutex.Lock()
+		tx.reads[key] = tx.cache.currentVersion(key)
+		tx.cache.mutex.Unlock()
+	}
+	return tx.cache.Get(key)
+}
+
+// Set stores the item with the global TTL when the transaction commits
+func (tx *TxOf[K]) Set(key K, data interface{}) {
+	tx.SetWithTTL(key, data, ItemExpireWithGlobalTTL)
+}
+
+// SetWithTTL stores the item with an individual TTL when the transaction commits
+func (tx *TxOf[K]) SetWithTTL(key K, data interface{}, ttl time.Duration) {
+	tx.write(key, childWrite{data: data, ttl: ttl})
+}
+
+// Remove removes the key when the transaction commits
+func (tx *TxOf[K]) Remove(key K) {
+	tx.write(key, childWrite{removed: true})
+}
+
+func (tx *TxOf[K]) write(key K, write childWrite) {
+	key = tx.cache.normalizeKey(key)
+	if _, exists := tx.writes[key]; !exists {
+		tx.order = append(tx.order, key)
+	}
+	tx.writes[key] = write
+}
+
+// commit applies the writes in the order their keys were first written, unless a key read by the transaction changed
+func (tx *TxOf[K]) commit() error {
+	cache := tx.cache
+	cache.mutex.Lock()
+	copier := cache.valueCopier
+	if !cache.copyOnSet {
+		copier = nil
+	}
+	cache.mutex.Unlock()
+	// the values are copied before the lock is taken for the commit, so nothing changes between the checks and the
+	// writes
+	if copier != nil {
+		for key, write := range tx.writes {
+			if !write.removed {
+				write.data = copier(write.data)
+				tx.writes[key] = write
+			}
+		}
+	}
+	cache.mutex.Lock()
+	if cache.isShutDown {
+		cache.mutex.Unlock()
+		return ErrCacheClosed
+	}
+	for key, version := range tx.reads {
+		if cache.currentVersion(key) != version {
+			cache.mutex.Unlock()
+			return ErrConflict
+		}
+	}
+
+	var inserted []K
+	var insertedData []interface{}
+	for _, key := range tx.order {
+		write := tx.writes[key]
+		if write.removed {
+			cache.remove(key)
+		} else if key, isNew := cache.set(key, write.data, write.ttl, itemOptions[K]{}); isNew {
+			inserted = append(inserted, key)
+			insertedData = append(insertedData, write.data)
+		}
+	}
+	newItemCallback := cache.newItemCallback
+	cache.mutex.Unlock()
+
+	if newItemCallback != nil {
+		for i, key := range inserted {
+			newItemCallback(key, insertedData[i])
+		}
+	}
+	cache.notifyExpiration()
+	return nil
+}
@@ -0,0 +1,109 @@
+package ttlcache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Txn(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("from", 100)
+	cache.Set("to", 0)
+
+	transfer := func(amount int) error {
+		return cache.Txn(func(tx *Tx) error {
+			from, _ := tx.Get("from")
+			if from.(int) < amount {
+				return errors.New("insufficient funds")
+			}
+			tx.Set("from", from.(int)-amount)
+			to, _ := tx.Get("to")
+			tx.Set("to", to.(int)+amount)
+			tx.Remove("pending")
+			_, exists := tx.Get("pending")
+			assert.False(t, exists, "Expected the transaction to see its own removal")
+			return nil
+		})
+	}
+
+	cache.Set("pending", true)
+	assert.Nil(t, transfer(30))
+	from, _ := cache.Get("from")
+	to, _ := cache.Get("to")
+	assert.Equal(t, 70, from)
+	assert.Equal(t, 30, to)
+	_, exists := cache.Get("pending")
+	assert.False(t, exists)
+
+	assert.EqualError(t, transfer(100), "insufficient funds")
+	from, _ = cache.Get("from")
+	assert.Equal(t, 70, from, "Expected the writes of a failed transaction to be discarded")
+}
+
+func TestCache_TxnConflict(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.Set("counter", 0)
+
+	err := cache.Txn(func(tx *Tx) error {
+		value, _ := tx.Get("counter")
+		cache.Set("counter", 10)
+		tx.Set("counter", value.(int)+1)
+		return nil
+	})
+	assert.Equal(t, ErrConflict, err)
+	value, _ := cache.Get("counter")
+	assert.Equal(t, 10, value, "Expected the writes of a conflicting transaction to be discarded")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := cache.Txn(func(tx *Tx) error {
+					value, _ := tx.Get("counter")
+					tx.Set("counter", value.(int)+1)
+					return nil
+				})
+				if err != ErrConflict {
+					assert.Nil(t, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	value, _ = cache.Get("counter")
+	assert.Equal(t, 30, value, "Expected no update to be lost")
+}
+
+func TestCache_TxnWithKeyNormalizer(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	cache.SetKeyNormalizer(FoldKeyCase)
+	cache.Set("counter", 1)
+
+	err := cache.Txn(func(tx *Tx) error {
+		tx.Set("Counter", 2)
+		value, _ := tx.Get("COUNTER")
+		assert.Equal(t, 2, value, "Expected the transaction to see its own write under another spelling")
+		tx.Set("counter", 3)
+		return nil
+	})
+	assert.Nil(t, err)
+	value, _ := cache.Get("counter")
+	assert.Equal(t, 3, value, "Expected the last write of the key to win")
+
+	err = cache.Txn(func(tx *Tx) error {
+		tx.Get("Counter")
+		cache.Set("counter", 10)
+		tx.Set("other", 1)
+		return nil
+	})
+	assert.Equal(t, ErrConflict, err, "Expected a read under another spelling to detect the write")
+}
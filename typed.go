@@ -0,0 +1,94 @@
+package ttlcache
+
+import (
+	"context"
+	"time"
+)
+
+// TypedCache is a CacheOf whose values all have the type V, so Get returns a V instead of an interface{} that needs a
+// type assertion. It is a thin wrapper, Untyped gives access to the rest of the API.
+type TypedCache[K comparable, V any] struct {
+	cache *CacheOf[K]
+}
+
+// NewTypedCache creates a cache with keys of type K and values of type V
+func NewTypedCache[K comparable, V any]() *TypedCache[K, V] {
+	return &TypedCache[K, V]{cache: NewCacheOf[K]()}
+}
+
+// Untyped returns the underlying cache, values stored through it must have the type V
+func (typed *TypedCache[K, V]) Untyped() *CacheOf[K] {
+	return typed.cache
+}
+
+// Set stores the value with the global TTL
+func (typed *TypedCache[K, V]) Set(key K, value V) {
+	typed.cache.Set(key, value)
+}
+
+// SetWithTTL stores the value with an individual TTL
+func (typed *TypedCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	typed.cache.SetWithTTL(key, value, ttl)
+}
+
+// Get looks up the value of the key, it returns the zero V when the key is missing
+func (typed *TypedCache[K, V]) Get(key K) (V, bool) {
+	return typedValue[V](typed.cache.Get(key))
+}
+
+// GetOrSetFunc returns the value of the key, or stores and returns the value fn returns, see CacheOf.GetOrSetFunc
+func (typed *TypedCache[K, V]) GetOrSetFunc(key K, fn func() (V, time.Duration)) (V, bool) {
+	return typedValue[V](typed.cache.GetOrSetFunc(key, func() (interface{}, time.Duration) {
+		return fn()
+	}))
+}
+
+// GetOrLoad returns the value of the key, or loads it with the loader of the cache, see CacheOf.GetOrLoad
+func (typed *TypedCache[K, V]) GetOrLoad(ctx context.Context, key K) (V, error) {
+	data, err := typed.cache.GetOrLoad(ctx, key)
+	value, _ := data.(V)
+	return value, err
+}
+
+// SetLoader sets the loader GetOrLoad calls for missing keys
+func (typed *TypedCache[K, V]) SetLoader(loader func(ctx context.Context, key K) (V, time.Duration, error)) {
+	typed.cache.SetLoader(func(ctx context.Context, key K) (interface{}, time.Duration, error) {
+		return loader(ctx, key)
+	})
+}
+
+// GetTTL returns the TTL of the key
+func (typed *TypedCache[K, V]) GetTTL(key K) (time.Duration, bool) {
+	return typed.cache.GetTTL(key)
+}
+
+// Has reports whether the key is stored, without extending its TTL
+func (typed *TypedCache[K, V]) Has(key K) bool {
+	return typed.cache.Has(key)
+}
+
+// Remove removes the key and reports whether it was stored
+func (typed *TypedCache[K, V]) Remove(key K) bool {
+	return typed.cache.Remove(key)
+}
+
+// Count returns the number of items in the cache
+func (typed *TypedCache[K, V]) Count() int {
+	return typed.cache.Count()
+}
+
+// Purge removes all items
+func (typed *TypedCache[K, V]) Purge() {
+	typed.cache.Purge()
+}
+
+// Close closes the cache, see CacheOf.Close
+func (typed *TypedCache[K, V]) Close() {
+	typed.cache.Close()
+}
+
+// typedValue converts the result of a lookup, a missing key gives the zero V
+func typedValue[V any](data interface{}, exists bool) (V, bool) {
+	value, _ := data.(V)
+	return value, exists
+}
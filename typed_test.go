@@ -0,0 +1,47 @@
+package ttlcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type session struct {
+	user string
+}
+
+func TestTypedCache(t *testing.T) {
+	cache := NewTypedCache[int, *session]()
+	defer cache.Close()
+
+	cache.Set(1, &session{user: "alice"})
+	value, exists := cache.Get(1)
+	assert.True(t, exists)
+	assert.Equal(t, "alice", value.user)
+	value, exists = cache.Get(2)
+	assert.False(t, exists)
+	assert.Nil(t, value)
+
+	value, cached := cache.GetOrSetFunc(2, func() (*session, time.Duration) {
+		return &session{user: "bob"}, time.Minute
+	})
+	assert.False(t, cached)
+	assert.Equal(t, "bob", value.user)
+
+	cache.SetLoader(func(ctx context.Context, key int) (*session, time.Duration, error) {
+		return &session{user: "loaded"}, 0, nil
+	})
+	value, err := cache.GetOrLoad(context.Background(), 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "loaded", value.user)
+	assert.Equal(t, 3, cache.Count())
+	assert.True(t, cache.Remove(3))
+	assert.Equal(t, 2, cache.Untyped().Count())
+
+	counters := NewTypedCache[string, int]()
+	defer counters.Close()
+	count, _ := counters.Get("missing")
+	assert.Equal(t, 0, count)
+}